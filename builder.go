@@ -0,0 +1,123 @@
+package lrucache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidOption is returned by NewCache for options that are invalid or
+// don't work together.
+var ErrInvalidOption = errors.New("lrucache: invalid option")
+
+// StatsSource is implemented by every cache of the package.
+type StatsSource interface {
+	Len() int
+	Cap() int
+	Stats() Stats
+}
+
+// MetricsRegistry exports statistics of caches created with WithMetrics,
+// such as metrics.Collector.
+type MetricsRegistry interface {
+	Register(name string, cache StatsSource) error
+}
+
+// NewCache creates a cache configured by opts. The capacity must be set with
+// WithCapacity. By default elements expire lazily on access, as in
+// CacheWithTTL2; WithBackgroundExpiration creates a CacheWithTTL instead and
+// WithShards a ShardedCache. Close stops the background work of the cache.
+func NewCache[K comparable, V any](opts ...Option[K, V]) (ICacheWithTTL[K, V], error) {
+	var o options[K, V]
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	var cache interface {
+		ICacheWithTTL[K, V]
+		StatsSource
+	}
+	switch {
+	case o.background:
+		cache, _ = NewWithTTL(o.cap, o.gcInterval, opts...)
+	case o.sharded:
+		cache = NewSharded(o.cap, opts...)
+	default:
+		cache = NewWithTTL2(o.cap, opts...)
+	}
+
+	if o.metrics != nil {
+		if err := o.metrics.Register(o.metricsName, cache); err != nil {
+			cache.Close()
+			return nil, err
+		}
+	}
+
+	return cache, nil
+}
+
+// validate checks the options passed to NewCache.
+func (o *options[K, V]) validate() error {
+	switch {
+	case o.cap <= 0:
+		return fmt.Errorf("%w, got %d", ErrInvalidCap, o.cap)
+	case o.background && o.gcInterval <= 0:
+		return fmt.Errorf("%w: GC interval must be positive, got %v", ErrInvalidOption, o.gcInterval)
+	case o.background && o.sharded:
+		return fmt.Errorf("%w: sharded caches expire elements lazily", ErrInvalidOption)
+	case o.shards < 0:
+		return fmt.Errorf("%w: number of shards must not be negative, got %d", ErrInvalidOption, o.shards)
+	case o.maxCost < 0:
+		return fmt.Errorf("%w: maximum cost must not be negative, got %d", ErrInvalidOption, o.maxCost)
+	case o.maxLifetime != 0 && !o.sliding:
+		return fmt.Errorf("%w: maximum lifetime requires sliding expiration", ErrInvalidOption)
+	case o.refreshAhead != 0 && o.loader == nil:
+		return fmt.Errorf("%w: refresh ahead requires a loader", ErrInvalidOption)
+	case o.defaultTTL != 0 && !validTTL(o.defaultTTL):
+		return fmt.Errorf("%w: default TTL must be positive or NoExpiration, got %v", ErrInvalidOption, o.defaultTTL)
+	case o.jitterError() != nil:
+		return o.jitterError()
+	case o.metrics != nil && o.metricsName == "":
+		return fmt.Errorf("%w: metrics name must not be empty", ErrInvalidOption)
+	}
+	for i, rule := range o.ttlRules {
+		if !validTTL(rule.TTL) {
+			return fmt.Errorf("%w: TTL of prefix %q must be positive or NoExpiration, got %v", ErrInvalidOption, rule.Prefix, rule.TTL)
+		}
+		for _, other := range o.ttlRules[:i] {
+			if other.Prefix == rule.Prefix {
+				return fmt.Errorf("%w: duplicate TTL rule for prefix %q", ErrInvalidOption, rule.Prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// WithCapacity sets the maximum number of elements of a cache created by
+// NewCache.
+func WithCapacity[K comparable, V any](cap int) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.cap = cap
+	}
+}
+
+// WithBackgroundExpiration makes NewCache create a CacheWithTTL, which
+// removes expired elements in a background goroutine every interval instead
+// of on access.
+func WithBackgroundExpiration[K comparable, V any](interval time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.background = true
+		o.gcInterval = interval
+	}
+}
+
+// WithMetrics makes NewCache register the cache in registry under name.
+func WithMetrics[K comparable, V any](registry MetricsRegistry, name string) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.metrics = registry
+		o.metricsName = name
+	}
+}
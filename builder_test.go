@@ -0,0 +1,186 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type fakeRegistry struct {
+	caches map[string]StatsSource
+}
+
+func (r *fakeRegistry) Register(name string, cache StatsSource) error {
+	if _, ok := r.caches[name]; ok {
+		return errors.New("already registered")
+	}
+	if r.caches == nil {
+		r.caches = make(map[string]StatsSource)
+	}
+	r.caches[name] = cache
+	return nil
+}
+
+func Test_NewCache(t *testing.T) {
+	var evicted []string
+
+	cache, err := NewCache[string, int](
+		WithCapacity[string, int](2),
+		WithEvictionPolicy(NewLFUPolicy[string, int]),
+		WithOnEvict(func(key string, _ int, reason EvictReason) {
+			evicted = append(evicted, key+":"+reason.String())
+		}),
+	)
+	require.NoError(t, err)
+	defer cache.Close()
+	assert.IsType(t, &CacheWithTTL2[string, int]{}, cache)
+	assert.Equal(t, 2, cache.Cap())
+
+	cache.Add("first", 1)
+	cache.Add("second", 2)
+	cache.Get("second")
+	cache.Add("third", 3)
+
+	assert.Equal(t, []string{"first:capacity"}, evicted)
+}
+
+func Test_NewCache_Strategies(t *testing.T) {
+	t.Run("background", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		cache, err := NewCache[string, int](
+			WithCapacity[string, int](10),
+			WithClock[string, int](clock),
+			WithBackgroundExpiration[string, int](time.Second),
+		)
+		require.NoError(t, err)
+		require.IsType(t, &CacheWithTTL[string, int]{}, cache)
+
+		cache.AddWithTTL("first", 1, time.Second)
+		require.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Microsecond*100)
+		advance(t, clock, time.Second*2)
+		assert.Equal(t, 0, cache.Len())
+
+		// the GC goroutine stops on Close
+		ttlCache := cache.(*CacheWithTTL[string, int])
+		cache.Close()
+		cache.Close()
+		ttlCache.AddWithTTL("second", 2, time.Second)
+		clock.Advance(time.Second * 2)
+		assert.Never(t, func() bool { return ttlCache.Len() == 0 }, time.Millisecond*20, time.Millisecond)
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		cache, err := NewCache[string, int](
+			WithCapacity[string, int](10),
+			WithShards[string, int](0),
+		)
+		require.NoError(t, err)
+		defer cache.Close()
+		require.IsType(t, &ShardedCache[string, int]{}, cache)
+		assert.Equal(t, 10, cache.Cap())
+	})
+}
+
+func Test_NewCache_Invalid(t *testing.T) {
+	loader := func(ctx context.Context, key string) (int, time.Duration, error) {
+		return 0, 0, nil
+	}
+
+	tests := []struct {
+		name string
+		opts []Option[string, int]
+		err  error
+	}{
+		{name: "no capacity", err: ErrInvalidCap},
+		{name: "zero capacity", opts: []Option[string, int]{WithCapacity[string, int](0)}, err: ErrInvalidCap},
+		{name: "negative capacity", opts: []Option[string, int]{WithCapacity[string, int](-1)}, err: ErrInvalidCap},
+		{name: "GC interval", opts: []Option[string, int]{WithBackgroundExpiration[string, int](0)}, err: ErrInvalidOption},
+		{name: "sharded background", opts: []Option[string, int]{WithBackgroundExpiration[string, int](time.Second), WithShards[string, int](4)}, err: ErrInvalidOption},
+		{name: "negative shards", opts: []Option[string, int]{WithShards[string, int](-1)}, err: ErrInvalidOption},
+		{name: "negative max cost", opts: []Option[string, int]{WithMaxCost[string, int](-1)}, err: ErrInvalidOption},
+		{name: "max lifetime", opts: []Option[string, int]{WithMaxLifetime[string, int](time.Minute)}, err: ErrInvalidOption},
+		{name: "refresh ahead", opts: []Option[string, int]{WithRefreshAhead[string, int](time.Minute)}, err: ErrInvalidOption},
+		{name: "default TTL", opts: []Option[string, int]{WithDefaultTTL[string, int](-time.Second)}, err: ErrInvalidOption},
+		{name: "rule TTL", opts: []Option[string, int]{WithTTLRules[int](TTLRule{Prefix: "user:", TTL: 0})}, err: ErrInvalidOption},
+		{name: "duplicate rule", opts: []Option[string, int]{WithTTLRules[int](
+			TTLRule{Prefix: "user:", TTL: time.Minute},
+			TTLRule{Prefix: "user:", TTL: time.Hour},
+		)}, err: ErrInvalidOption},
+		{name: "negative jitter", opts: []Option[string, int]{WithTTLJitter[string, int](-0.1)}, err: ErrInvalidOption},
+		{name: "full jitter", opts: []Option[string, int]{WithTTLJitter[string, int](1)}, err: ErrInvalidOption},
+		{name: "jitter bounds", opts: []Option[string, int]{WithTTLJitterBounds[string, int](time.Second, 0)}, err: ErrInvalidOption},
+		{name: "jitter fraction and bounds", opts: []Option[string, int]{
+			WithTTLJitter[string, int](0.1),
+			WithTTLJitterBounds[string, int](0, time.Second),
+		}, err: ErrInvalidOption},
+		{name: "metrics name", opts: []Option[string, int]{WithMetrics[string, int](&fakeRegistry{}, "")}, err: ErrInvalidOption},
+		{name: "valid", opts: []Option[string, int]{
+			WithSlidingExpiration[string, int](),
+			WithMaxLifetime[string, int](time.Minute),
+			WithLoader(loader),
+			WithRefreshAhead[string, int](time.Minute),
+			WithDefaultTTL[string, int](NoExpiration),
+			WithTTLRules[int](TTLRule{Prefix: "user:", TTL: time.Minute}),
+			WithTTLJitter[string, int](0.1),
+			WithJitterSeed[string, int](1),
+		}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if tt.err != ErrInvalidCap {
+				opts = append([]Option[string, int]{WithCapacity[string, int](10)}, opts...)
+			}
+
+			cache, err := NewCache(opts...)
+			if tt.err == nil {
+				require.NoError(t, err)
+				cache.Close()
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+			assert.Nil(t, cache)
+		})
+	}
+}
+
+func Test_NewCache_Metrics(t *testing.T) {
+	var registry fakeRegistry
+
+	cache, err := NewCache[string, int](WithCapacity[string, int](10), WithMetrics[string, int](&registry, "default"))
+	require.NoError(t, err)
+	cache.Add("first", 1)
+	assert.Equal(t, uint64(1), registry.caches["default"].Stats().Inserts)
+
+	// the cache is closed if it can't be registered
+	_, err = NewCache[string, int](
+		WithCapacity[string, int](10),
+		WithBackgroundExpiration[string, int](time.Second),
+		WithMetrics[string, int](&registry, "default"),
+	)
+	assert.Error(t, err)
+}
+
+func Test_New_InvalidCap(t *testing.T) {
+	constructors := map[string]func(cap int){
+		"New":         func(cap int) { New[string, int](cap) },
+		"NewWithTTL":  func(cap int) { NewWithTTL[string, int](cap, time.Second) },
+		"NewWithTTL2": func(cap int) { NewWithTTL2[string, int](cap) },
+		"NewSharded":  func(cap int) { NewSharded[string, int](cap) },
+	}
+
+	for name, newCache := range constructors {
+		newCache := newCache
+		t.Run(name, func(t *testing.T) {
+			for _, cap := range []int{0, -1} {
+				assert.PanicsWithError(t, fmt.Sprintf("%v, got %d", ErrInvalidCap, cap), func() { newCache(cap) })
+			}
+		})
+	}
+}
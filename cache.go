@@ -1,104 +1,486 @@
 package lrucache
 
 import (
-	"container/list"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-type Element struct {
-	key           string
-	value         any
-	expQueueIndex int // -1 if element has no TTL
+type Element[K comparable, V any] struct {
+	key           K
+	value         V
+	expQueueIndex int            // -1 if element has no TTL
+	expPrev       *Element[K, V] // links of the timing wheel slot
+	expNext       *Element[K, V]
 	expiresAt     time.Time
+	staleAt       time.Time     // the value is refreshed after staleAt and removed after expiresAt
+	idle          time.Duration // sliding expiration timeout, 0 if expiration is fixed
+	deadline      time.Time     // sliding expiration limit, zero if there is no limit
+	err           error         // not nil for negative entries
+	cost          int64
+
+	// links of the eviction policy lists
+	prev, next *Element[K, V]
+	list       *elementList[K, V]
+	freq       uint64        // number of accesses, counted by the LFU policy
+	refs       atomic.Uint32 // reads marked by the CLOCK and S3-FIFO policies
+}
+
+// Key returns the key of the element.
+func (e *Element[K, V]) Key() K {
+	return e.key
+}
+
+type Cache[K comparable, V any] struct {
+	cap      int
+	data     map[K]*Element[K, V]
+	mutex    sync.RWMutex
+	queue    EvictionPolicy[K, V] // eviction order
+	expQueue expirations[K, V]
+	clock    Clock
+	reads    readBuffer[K, V] // reads not applied to queue yet
+	markRead bool             // reads call queue.Access instead of using reads
+
+	onEvict func(key K, value V, reason EvictReason)
+	evicted []eviction[K, V] // evictions to report once the lock is released
+
+	counters counters
+
+	negativeTTL time.Duration // TTL of loader errors, 0 disables negative caching
+	staleTTL    time.Duration // how long values are served after their TTL
+	refresher   *refresher[K, V]
+	sliding     bool          // AddWithTTL sets sliding expiration
+	maxLifetime time.Duration // limit of sliding expiration, 0 if there is no limit
+	defaultTTL  time.Duration // TTL applied by Add, 0 or NoExpiration if there is none
+	ttlRule     func(key K) (ttl time.Duration, ok bool)
+	jitter      *ttlJitter  // nil if TTLs are used as given
+	admission   *tinyLFU[K] // nil if every new key is admitted
+
+	maxCost  int64 // limit of the total cost, 0 if only the number of elements is limited
+	usedCost int64
+	sizer    Sizer[K, V]
+
+	loadMutex sync.Mutex
+	loads     map[K]*loadCall[V] // loads in progress for GetOrLoad
 }
 
-type Cache struct {
-	cap   int
-	data  map[string]*list.Element
-	mutex sync.RWMutex
-	queue *list.List
+// New creates a cache of cap elements. It panics with ErrInvalidCap if cap is
+// not positive, NewCache returns the error instead.
+func New[K comparable, V any](cap int, opts ...Option[K, V]) *Cache[K, V] {
+	cache := &Cache[K, V]{}
+	cache.init(cap, opts)
+	// Cache doesn't expire elements, so it stores them without TTL
+	cache.defaultTTL, cache.ttlRule = 0, nil
+
+	return cache
 }
 
-func New(cap int) *Cache {
-	return &Cache{
-		cap:   cap,
-		data:  make(map[string]*list.Element, cap),
-		queue: list.New(),
+func (c *Cache[K, V]) init(cap int, opts []Option[K, V]) {
+	if cap <= 0 {
+		panic(fmt.Errorf("%w, got %d", ErrInvalidCap, cap))
+	}
+
+	var o options[K, V]
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	newPolicy := o.newPolicy
+	if newPolicy == nil {
+		newPolicy = NewLRUPolicy[K, V]
+	}
+
+	c.clock = o.clock
+	if c.clock == nil {
+		c.clock = SystemClock{}
+	}
+
+	c.cap = cap
+	c.data = make(map[K]*Element[K, V], cap)
+	c.queue = newPolicy(cap)
+	if o.timingWheel {
+		c.expQueue = newTimingWheel[K, V](c.clock.Now())
+	} else {
+		c.expQueue = newExpirationQueue[K, V]()
+	}
+	c.reads = newReadBuffer[K, V]()
+	c.onEvict = o.onEvict
+	c.negativeTTL = o.negativeTTL
+	c.staleTTL = o.staleTTL
+	c.sliding = o.sliding
+	c.maxLifetime = o.maxLifetime
+	c.defaultTTL = o.defaultTTL
+	c.ttlRule = o.ttlRule
+	c.jitter = newTTLJitter(o)
+	c.maxCost = o.maxCost
+	c.sizer = o.sizer
+	if o.loader != nil {
+		c.refresher = newRefresher(o)
 	}
+	if o.tinyLFU {
+		hash := o.hash
+		if hash == nil {
+			hash = newDefaultHash[K]()
+		}
+		c.admission = newTinyLFU(cap, hash)
+	}
+	// the admission filter counts reads when they are applied to the queue
+	_, concurrent := c.queue.(concurrentAccessPolicy)
+	c.markRead = concurrent && c.admission == nil
 }
 
-func (c *Cache) Cap() int {
+func (c *Cache[K, V]) Cap() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	return c.cap
 }
 
-func (c *Cache) Len() int {
+func (c *Cache[K, V]) Len() int {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
 	return len(c.data)
 }
 
-func (c *Cache) Clear() {
+func (c *Cache[K, V]) Clear() {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	defer c.unlock()
+
+	if c.onEvict != nil {
+		for _, elem := range c.data {
+			if elem.err == nil {
+				c.notify(elem.key, elem.value, EvictReasonCleared)
+			}
+		}
+	}
 
-	c.data = make(map[string]*list.Element, c.cap)
-	c.queue = list.New()
+	c.data = make(map[K]*Element[K, V], c.cap)
+	c.usedCost = 0
+	c.queue.Clear()
+	c.expQueue.clear()
+	// drop the recorded reads of removed elements
+	c.drainReads()
 }
 
-func (c *Cache) Add(key string, value any) {
+// Close does nothing, Cache has no background work.
+func (c *Cache[K, V]) Close() {}
+
+func (c *Cache[K, V]) Add(key K, value V) {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	defer c.unlock()
 
-	// if element already exists just update element position in queue
-	if elem, ok := c.data[key]; ok {
-		elem.Value = Element{
-			key:   key,
-			value: value,
-		}
-		c.queue.MoveToFront(elem)
-		return
+	if elem := c.add(key, value); elem != nil {
+		c.setDefaultTTL(elem)
 	}
+}
+
+// Replace changes the value of an existing key, keeping its expiration as it
+// is. ok is false if there is no such key.
+func (c *Cache[K, V]) Replace(key K, value V) (ok bool) {
+	c.mutex.Lock()
+	defer c.unlock()
 
-	// if cache is full displace the value that was not requested the most
-	if c.queue.Len() == c.cap {
-		last := c.queue.Back()
-		delete(c.data, last.Value.(Element).key)
-		c.queue.Remove(last)
+	if elem, ok := c.data[key]; !ok || elem.err != nil {
+		return false
 	}
+	return c.add(key, value) != nil
+}
 
-	// add new element
-	newElem := c.queue.PushFront(Element{
-		key:   key,
-		value: value,
-	})
-	c.data[key] = newElem
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	value, ok, err := c.lookup(key)
+	return value, ok && err == nil
+}
+
+func (c *Cache[K, V]) Remove(key K) {
+	c.mutex.Lock()
+	defer c.unlock()
+
+	if elem, ok := c.data[key]; ok {
+		c.removeElement(elem, EvictReasonRemoved)
+	}
 }
 
-func (c *Cache) Get(key string) (any, bool) {
+// lookup returns the value of the key or, for a negative entry, its error.
+// ok is false if the key is not cached.
+//
+// Reads only take the read lock and are applied to the queue in batches, see
+// readBuffer, or right away if the policy only marks read elements. Elements
+// with sliding expiration are the exception as every read has to update the
+// expiration queue.
+func (c *Cache[K, V]) lookup(key K) (value V, ok bool, err error) {
 	c.mutex.RLock()
 	elem, ok := c.data[key]
+	if !ok {
+		c.mutex.RUnlock()
+		c.counters.misses.Add(1)
+		return value, false, nil
+	}
+
+	value, err = elem.value, elem.err
+	sliding := err == nil && elem.idle > 0 && elem.expQueueIndex != -1
+	refresh := !sliding && c.needsRefresh(elem)
 	c.mutex.RUnlock()
 
-	// update position in queue if element exists
-	if ok {
+	if err != nil {
+		c.counters.negativeHits.Add(1)
+		c.recordRead(elem)
+		var zero V
+		return zero, true, err
+	}
+	c.counters.hits.Add(1)
+
+	if sliding {
 		c.mutex.Lock()
-		defer c.mutex.Unlock()
+		// the element could have been replaced or removed in the meantime
+		if c.data[key] == elem {
+			if elem.idle > 0 && elem.expQueueIndex != -1 {
+				c.slide(elem)
+			}
+			c.queue.Access(elem)
+		}
+		refresh = c.needsRefresh(elem)
+		c.mutex.Unlock()
+	} else {
+		c.recordRead(elem)
+	}
 
-		c.queue.MoveToFront(elem)
-		return elem.Value.(Element).value, true
+	if refresh {
+		c.refresher.start(key, func(value V, ttl time.Duration) {
+			c.storeRefreshed(elem, value, ttl)
+		})
 	}
+	return value, true, nil
+}
 
-	return nil, false
+// needsRefresh must be called with the lock held.
+func (c *Cache[K, V]) needsRefresh(element *Element[K, V]) bool {
+	return c.refresher != nil && element.err == nil && element.expQueueIndex != -1 && c.refresher.due(c.clock.Now(), element.staleAt)
 }
 
-func (c *Cache) Remove(key string) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// recordRead records the read of elem and applies the recorded reads to the
+// queue once a batch is collected.
+func (c *Cache[K, V]) recordRead(elem *Element[K, V]) {
+	if c.markRead {
+		c.queue.Access(elem)
+		return
+	}
+	if stripe := c.reads.record(elem); stripe != nil {
+		c.mutex.Lock()
+		stripe.drain(c.access)
+		c.mutex.Unlock()
+	}
+}
+
+// drainReads applies all recorded reads to the queue.
+// Must be called with the write lock held.
+func (c *Cache[K, V]) drainReads() {
+	c.reads.drain(c.access)
+}
+
+// access reports a recorded read to the policy unless the element was
+// removed since. Must be called with the write lock held.
+func (c *Cache[K, V]) access(elem *Element[K, V]) {
+	if c.admission != nil {
+		c.admission.increment(elem.key)
+	}
+	if c.data[elem.key] == elem {
+		c.queue.Access(elem)
+	}
+}
 
+// add puts the value to the cache and returns its element, or nil if the
+// value was rejected. The expiration of an existing element is left
+// untouched. Must be called with the write lock held.
+func (c *Cache[K, V]) add(key K, value V) *Element[K, V] {
+	elem, _ := c.put(key, value, nil, c.costOf(key, value))
+	return elem
+}
+
+// addNegative puts a negative entry with err to the cache and returns its
+// element, or nil if it was rejected. Negative entries cost 1. Must be called
+// with the write lock held.
+func (c *Cache[K, V]) addNegative(key K, err error) *Element[K, V] {
+	var zero V
+	elem, _ := c.put(key, zero, err, 1)
+	return elem
+}
+
+// put returns ErrTooLarge or ErrNotAdmitted if the value was rejected. A
+// value that is too large removes the old value of the key, so that it isn't
+// read after the update, otherwise the cache is left unchanged. Must be
+// called with the write lock held.
+func (c *Cache[K, V]) put(key K, value V, err error, cost int64) (*Element[K, V], error) {
+	if c.maxCost > 0 && cost > c.maxCost {
+		if elem, ok := c.data[key]; ok {
+			c.removeElement(elem, EvictReasonReplaced)
+		}
+		c.counters.rejections.Add(1)
+		return nil, ErrTooLarge
+	}
+
+	if c.admission != nil {
+		c.admission.increment(key)
+	}
+
+	// if element already exists just update its value and position in queue
 	if elem, ok := c.data[key]; ok {
-		c.queue.Remove(elem)
-		delete(c.data, key)
+		if elem.err == nil {
+			c.notify(elem.key, elem.value, EvictReasonReplaced)
+		}
+
+		elem.value = value
+		elem.err = err
+		c.usedCost += cost - elem.cost
+		elem.cost = cost
+		if c.overCost(0, 0) {
+			// take the element out of the policy so that it is not evicted
+			// to make room for itself
+			c.drainReads()
+			c.queue.Remove(elem, EvictReasonReplaced)
+			c.makeRoom(key, 0, 0)
+			c.queue.Add(elem)
+		} else {
+			c.queue.Access(elem)
+		}
+		c.countPut(err, true)
+		return elem, nil
+	}
+
+	// if cache is full displace the elements chosen by the policy
+	if c.overCost(1, cost) {
+		c.drainReads()
+		if victim := c.queue.Victim(key); victim != nil && c.admission != nil && !c.admission.admit(key, victim.key) {
+			c.counters.rejections.Add(1)
+			return nil, ErrNotAdmitted
+		}
+		c.makeRoom(key, 1, cost)
+	}
+
+	// add new element
+	elem := &Element[K, V]{
+		key:           key,
+		value:         value,
+		expQueueIndex: -1,
+		err:           err,
+		cost:          cost,
+	}
+	c.data[key] = elem
+	c.usedCost += cost
+	c.queue.Add(elem)
+	c.countPut(err, false)
+
+	return elem, nil
+}
+
+// overCost reports whether adding n elements of the given total cost would
+// exceed the capacity or the maximum cost. Must be called with the lock held.
+func (c *Cache[K, V]) overCost(n int, cost int64) bool {
+	return len(c.data)+n > c.cap || c.maxCost > 0 && c.usedCost+cost > c.maxCost
+}
+
+// makeRoom evicts the elements chosen by the policy until n elements of the
+// given total cost fit. Must be called with the write lock held.
+func (c *Cache[K, V]) makeRoom(key K, n int, cost int64) {
+	for c.overCost(n, cost) {
+		victim := c.queue.Victim(key)
+		if victim == nil {
+			return
+		}
+		c.removeElement(victim, EvictReasonCapacity)
+	}
+}
+
+// setTTL sets the expiration of a value added with ttl, sliding if the cache
+// was created with WithSlidingExpiration. Must be called with the write lock held.
+func (c *Cache[K, V]) setTTL(elem *Element[K, V], ttl time.Duration) {
+	if ttl == NoExpiration {
+		c.removeExpiration(elem)
+		return
+	}
+	if c.jitter != nil {
+		ttl = c.jitter.apply(ttl)
+	}
+	if c.sliding {
+		c.setSlidingTTL(elem, ttl, c.maxLifetime)
+		return
+	}
+
+	elem.idle, elem.deadline = 0, time.Time{}
+	c.expireAt(elem, c.clock.Now().Add(ttl))
+}
+
+// expireAt makes the value stale at staleAt and expires it staleTTL later.
+// Must be called with the write lock held.
+func (c *Cache[K, V]) expireAt(elem *Element[K, V], staleAt time.Time) {
+	elem.staleAt = staleAt
+	c.setExpiration(elem, staleAt.Add(c.staleTTL))
+}
+
+// setExpiration must be called with the write lock held.
+func (c *Cache[K, V]) setExpiration(elem *Element[K, V], expiresAt time.Time) {
+	elem.expiresAt = expiresAt
+	c.expQueue.schedule(elem)
+}
+
+// removeExpiration must be called with the write lock held.
+func (c *Cache[K, V]) removeExpiration(elem *Element[K, V]) {
+	c.expQueue.cancel(elem)
+}
+
+// removeElement must be called with the write lock held.
+func (c *Cache[K, V]) removeElement(elem *Element[K, V], reason EvictReason) {
+	c.removeExpiration(elem)
+	c.queue.Remove(elem, reason)
+	delete(c.data, elem.key)
+	c.usedCost -= elem.cost
+
+	c.counters.countRemoval(reason)
+	if elem.err == nil {
+		c.notify(elem.key, elem.value, reason)
 	}
 }
+
+func (c *Cache[K, V]) countPut(err error, exists bool) {
+	switch {
+	case err != nil:
+		c.counters.negativeInserts.Add(1)
+	case exists:
+		c.counters.updates.Add(1)
+	default:
+		c.counters.inserts.Add(1)
+	}
+}
+
+// removeExpired removes elements that expired before now.
+// Must be called with the write lock held.
+func (c *Cache[K, V]) removeExpired(now time.Time) {
+	c.expQueue.expire(now, func(elem *Element[K, V]) {
+		c.removeElement(elem, EvictReasonExpired)
+	})
+}
+
+func (c *Cache[K, V]) ttl(key K) (time.Duration, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	elem, ok := c.data[key]
+	if !ok {
+		return 0, false
+	}
+
+	if elem.err != nil {
+		return 0, false
+	}
+	if elem.expQueueIndex == -1 {
+		return NoExpiration, true
+	}
+
+	ttl := elem.expiresAt.Sub(c.clock.Now())
+	if ttl <= 0 {
+		return 0, false
+	}
+
+	return ttl, true
+}
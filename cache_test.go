@@ -8,7 +8,7 @@ import (
 )
 
 func BenchmarkCache_Add(b *testing.B) {
-	cache := New(1000)
+	cache := NewString(1000)
 
 	for i := 0; i < b.N; i++ {
 		cache.Add(strconv.Itoa(i), i)
@@ -16,7 +16,7 @@ func BenchmarkCache_Add(b *testing.B) {
 }
 
 func BenchmarkCache_Get(b *testing.B) {
-	cache := New(1000)
+	cache := NewString(1000)
 
 	for i := 0; i < 1000; i++ {
 		cache.Add(strconv.Itoa(i), i)
@@ -29,7 +29,7 @@ func BenchmarkCache_Get(b *testing.B) {
 
 func Test_Cache_New(t *testing.T) {
 	capacity := 5
-	cache := New(capacity)
+	cache := NewString(capacity)
 
 	require.NotNil(t, cache)
 	assert.NotNil(t, cache.data)
@@ -39,14 +39,14 @@ func Test_Cache_New(t *testing.T) {
 
 func Test_Cache_Cap(t *testing.T) {
 	capacity := 5
-	cache := New(capacity)
+	cache := NewString(capacity)
 
 	assert.Equal(t, capacity, cache.Cap())
 }
 
 func Test_Cache_Len(t *testing.T) {
 	capacity := 3
-	cache := New(capacity)
+	cache := NewString(capacity)
 
 	cases := []struct {
 		name        string
@@ -89,7 +89,7 @@ func Test_Cache_Len(t *testing.T) {
 }
 
 func Test_Cache_Add(t *testing.T) {
-	cache := New(3)
+	cache := NewString(3)
 
 	cases := []struct {
 		name        string
@@ -133,7 +133,7 @@ func Test_Cache_Add(t *testing.T) {
 }
 
 func Test_Cache_Update(t *testing.T) {
-	cache := New(3)
+	cache := NewString(3)
 
 	cache.Add("key", "value")
 	cache.Add("key", "another value")
@@ -148,7 +148,7 @@ func Test_Cache_Update(t *testing.T) {
 
 func Test_Cache_Clear(t *testing.T) {
 	capacity := 5
-	cache := New(capacity)
+	cache := NewString(capacity)
 
 	cache.Add("key", "value")
 
@@ -162,7 +162,7 @@ func Test_Cache_Clear(t *testing.T) {
 
 func Test_Cache_Get(t *testing.T) {
 	capacity := 3
-	cache := New(capacity)
+	cache := NewString(capacity)
 
 	cache.Add("first", 1)
 	cache.Add("second", struct{ n int }{2})
@@ -219,7 +219,7 @@ func Test_Cache_Get(t *testing.T) {
 
 func Test_Cache_Remove(t *testing.T) {
 	capacity := 3
-	cache := New(capacity)
+	cache := NewString(capacity)
 
 	cache.Add("first", 1)
 	cache.Add("second", 2)
@@ -256,3 +256,38 @@ func Test_Cache_Remove(t *testing.T) {
 		})
 	}
 }
+
+func Test_Cache_TypedKeys(t *testing.T) {
+	type id struct {
+		tenant string
+		n      int
+	}
+
+	cache := New[id, int](2)
+
+	cache.Add(id{"a", 1}, 1)
+	cache.Add(id{"b", 1}, 2)
+	cache.Add(id{"a", 2}, 3)
+
+	value, ok := cache.Get(id{"a", 1})
+	assert.Equal(t, 0, value)
+	assert.Equal(t, false, ok)
+
+	value, ok = cache.Get(id{"a", 2})
+	assert.Equal(t, 3, value)
+	assert.Equal(t, true, ok)
+}
+
+func BenchmarkCache_GetParallel(b *testing.B) {
+	cache := NewString(1000)
+
+	for i := 0; i < 1000; i++ {
+		cache.Add(strconv.Itoa(i), i)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Get(strconv.Itoa(i % 1000))
+		}
+	})
+}
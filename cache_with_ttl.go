@@ -1,181 +1,68 @@
 package lrucache
 
 import (
-	"container/heap"
-	"container/list"
 	"context"
-	"fmt"
 	"time"
 )
 
-type CacheWithTTL struct {
-	Cache
-	expQueue expirationQueue
+type CacheWithTTL[K comparable, V any] struct {
+	Cache[K, V]
 	expCheck time.Duration
+	cancel   context.CancelFunc
 }
 
-func NewWithTTL(cap int, expCheck time.Duration) (*CacheWithTTL, context.CancelFunc) {
-	cache := &CacheWithTTL{
-		Cache: Cache{
-			cap:   cap,
-			data:  make(map[string]*list.Element, cap),
-			queue: list.New(),
-		},
-		expQueue: newExpirationQueue(),
+// NewWithTTL creates a cache of cap elements that removes expired elements
+// every expCheck. Like New, it panics if cap is not positive.
+func NewWithTTL[K comparable, V any](cap int, expCheck time.Duration, opts ...Option[K, V]) (*CacheWithTTL[K, V], context.CancelFunc) {
+	cache := &CacheWithTTL[K, V]{
 		expCheck: expCheck,
 	}
+	cache.init(cap, opts)
 
 	ctx, cancel := context.WithCancel(context.Background())
+	cache.cancel = cancel
 	go cache.StartGC(ctx)
 
 	return cache, cancel
 }
 
-func (c *CacheWithTTL) StartGC(ctx context.Context) {
+func (c *CacheWithTTL[K, V]) StartGC(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("CANCEL")
 			return
-		default:
-			<-time.After(c.expCheck)
-
-			if c.expQueue.Len() == 0 {
-				break
+		case <-c.clock.After(c.expCheck):
+			if ctx.Err() != nil {
+				return
 			}
 
-			c.mutex.Lock()
-
 			// check and remove expired elements
-			for c.expQueue.Len() > 0 {
-				last := c.expQueue[0]
-
-				if last.Value.(*Element).expiresAt.Before(time.Now()) {
-					delete(c.data, last.Value.(*Element).key)
-					c.queue.Remove(last)
-					heap.Pop(&c.expQueue)
-				} else {
-					break
-				}
-			}
-
-			c.mutex.Unlock()
+			c.mutex.Lock()
+			c.removeExpired(c.clock.Now())
+			c.unlock()
 		}
 	}
 }
 
-func (c *CacheWithTTL) Cap() int {
-	return c.cap
-}
-
-func (c *CacheWithTTL) Len() int {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-
-	return len(c.data)
-}
-
-func (c *CacheWithTTL) Clear() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.data = make(map[string]*list.Element, c.cap)
-	c.queue = list.New()
-	c.expQueue = newExpirationQueue()
-}
-
-func (c *CacheWithTTL) Add(key string, value any) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// if element already exists just update element position in queue
-	if elem, ok := c.data[key]; ok {
-		elem.Value = &Element{
-			key:   key,
-			value: value,
-		}
-		c.queue.MoveToFront(elem)
-		return
-	}
-
-	// if cache is full displace the value that was not requested the most
-	if c.queue.Len() == c.cap {
-		last := c.queue.Back()
-		delete(c.data, last.Value.(*Element).key)
-		c.queue.Remove(last)
+// Close stops the goroutine started by NewWithTTL, like the CancelFunc it
+// returns.
+func (c *CacheWithTTL[K, V]) Close() {
+	if c.cancel != nil {
+		c.cancel()
 	}
-
-	// add new element
-	newElem := c.queue.PushFront(&Element{
-		key:           key,
-		value:         value,
-		expQueueIndex: -1,
-	})
-	c.data[key] = newElem
 }
 
-func (c *CacheWithTTL) AddWithTTL(key string, value interface{}, ttl time.Duration) {
+func (c *CacheWithTTL[K, V]) AddWithTTL(key K, value V, ttl time.Duration) {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// update element if it already exists
-	if elem, ok := c.data[key]; ok {
-		elem.Value.(*Element).expiresAt = time.Now().Add(ttl)
-
-		if elem.Value.(*Element).expQueueIndex == -1 {
-			c.expQueue.Push(elem)
-		} else {
-			heap.Fix(&c.expQueue, elem.Value.(*Element).expQueueIndex)
-		}
-
-		c.queue.MoveToFront(elem)
-		return
-	}
+	defer c.unlock()
 
-	// if cache is full displace the value that was not requested the most
-	if c.queue.Len() == c.cap {
-		last := c.queue.Back()
-		delete(c.data, last.Value.(*Element).key)
-		c.queue.Remove(last)
+	if elem := c.add(key, value); elem != nil {
+		c.setTTL(elem, ttl)
 	}
-
-	// add new element
-	newElem := c.queue.PushFront(&Element{
-		key:           key,
-		value:         value,
-		expiresAt:     time.Now().Add(ttl),
-		expQueueIndex: -1,
-	})
-	c.data[key] = newElem
-	c.expQueue.Push(newElem)
 }
 
-func (c *CacheWithTTL) Get(key string) (any, bool) {
-	c.mutex.RLock()
-	elem, ok := c.data[key]
-	c.mutex.RUnlock()
-
-	// update position in queue if element exists
-	if ok {
-		c.mutex.Lock()
-		defer c.mutex.Unlock()
-
-		c.queue.MoveToFront(elem)
-		return elem.Value.(*Element).value, true
-	}
-
-	return nil, false
-}
-
-func (c *CacheWithTTL) Remove(key string) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	if elem, ok := c.data[key]; ok {
-		if elem.Value.(*Element).expQueueIndex != -1 {
-			heap.Remove(&c.expQueue, elem.Value.(*Element).expQueueIndex)
-		}
-		c.queue.Remove(elem)
-		delete(c.data, key)
-	}
+// TTL returns the time left until the element expires, or NoExpiration if the
+// element was added without TTL. ok is false if there is no such element.
+func (c *CacheWithTTL[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	return c.ttl(key)
 }
@@ -9,7 +9,7 @@ import (
 )
 
 func BenchmarkCacheTTL_Add(b *testing.B) {
-	cache, cancel := NewWithTTL(1000, time.Second)
+	cache, cancel := NewStringWithTTL(1000, time.Second)
 	defer cancel()
 
 	for i := 0; i < b.N; i++ {
@@ -18,7 +18,7 @@ func BenchmarkCacheTTL_Add(b *testing.B) {
 }
 
 func BenchmarkCacheTTL_Get(b *testing.B) {
-	cache, cancel := NewWithTTL(1000, time.Second)
+	cache, cancel := NewStringWithTTL(1000, time.Second)
 	defer cancel()
 
 	for i := 0; i < 1000; i++ {
@@ -32,7 +32,7 @@ func BenchmarkCacheTTL_Get(b *testing.B) {
 
 func Test_NewTTL(t *testing.T) {
 	capacity := 5
-	cache, cancel := NewWithTTL(capacity, time.Second)
+	cache, cancel := NewStringWithTTL(capacity, time.Second)
 	defer cancel()
 
 	require.NotNil(t, cache)
@@ -43,7 +43,8 @@ func Test_NewTTL(t *testing.T) {
 
 func Test_NewTTL_AddWithExpiration(t *testing.T) {
 	capacity := 4
-	cache, cancel := NewWithTTL(capacity, time.Second)
+	clock := NewFakeClock(time.Now())
+	cache, cancel := NewStringWithTTL(capacity, time.Second, WithClock[string, any](clock))
 	defer cancel()
 
 	cache.Add("first", "value")
@@ -56,8 +57,11 @@ func Test_NewTTL_AddWithExpiration(t *testing.T) {
 	assert.Equal(t, 4, cache.queue.Len())
 	assert.Equal(t, 3, cache.expQueue.Len())
 
-	time.Sleep(time.Second * 4)
+	// wait for the background goroutine to wait for the clock
+	require.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Millisecond)
+	clock.Advance(time.Second * 4)
 
+	require.Eventually(t, func() bool { return cache.Len() == 2 }, time.Second, time.Millisecond)
 	assert.Equal(t, 2, cache.Len())
 	assert.Equal(t, 2, cache.queue.Len())
 	assert.Equal(t, 1, cache.expQueue.Len())
@@ -65,7 +69,7 @@ func Test_NewTTL_AddWithExpiration(t *testing.T) {
 
 func Test_CacheTTL_Cap(t *testing.T) {
 	capacity := 5
-	cache, cancel := NewWithTTL(capacity, time.Second)
+	cache, cancel := NewStringWithTTL(capacity, time.Second)
 	defer cancel()
 
 	assert.Equal(t, capacity, cache.Cap())
@@ -73,7 +77,7 @@ func Test_CacheTTL_Cap(t *testing.T) {
 
 func Test_CacheTTL_Len(t *testing.T) {
 	capacity := 3
-	cache, cancel := NewWithTTL(capacity, time.Second)
+	cache, cancel := NewStringWithTTL(capacity, time.Second)
 	defer cancel()
 
 	cases := []struct {
@@ -117,7 +121,7 @@ func Test_CacheTTL_Len(t *testing.T) {
 }
 
 func Test_CacheTTL_Add(t *testing.T) {
-	cache, cancel := NewWithTTL(3, time.Second)
+	cache, cancel := NewStringWithTTL(3, time.Second)
 	defer cancel()
 
 	cases := []struct {
@@ -162,7 +166,7 @@ func Test_CacheTTL_Add(t *testing.T) {
 }
 
 func Test_CacheTTL_Update(t *testing.T) {
-	cache, cancel := NewWithTTL(3, time.Second)
+	cache, cancel := NewStringWithTTL(3, time.Second)
 	defer cancel()
 
 	cache.Add("key", "value")
@@ -178,7 +182,7 @@ func Test_CacheTTL_Update(t *testing.T) {
 
 func Test_CacheTTL_Clear(t *testing.T) {
 	capacity := 5
-	cache, cancel := NewWithTTL(capacity, time.Second)
+	cache, cancel := NewStringWithTTL(capacity, time.Second)
 	defer cancel()
 
 	cache.Add("key", "value")
@@ -194,7 +198,7 @@ func Test_CacheTTL_Clear(t *testing.T) {
 
 func Test_CacheTTL_Get(t *testing.T) {
 	capacity := 3
-	cache, cancel := NewWithTTL(capacity, time.Second)
+	cache, cancel := NewStringWithTTL(capacity, time.Second)
 	defer cancel()
 
 	cache.Add("first", 1)
@@ -252,7 +256,7 @@ func Test_CacheTTL_Get(t *testing.T) {
 
 func Test_CacheTTL_Remove(t *testing.T) {
 	capacity := 3
-	cache, cancel := NewWithTTL(capacity, time.Second)
+	cache, cancel := NewStringWithTTL(capacity, time.Second)
 	defer cancel()
 
 	cache.Add("first", 1)
@@ -290,3 +294,19 @@ func Test_CacheTTL_Remove(t *testing.T) {
 		})
 	}
 }
+
+func Test_CacheTTL_TypedKeys(t *testing.T) {
+	cache, cancel := NewWithTTL[int, string](3, time.Second)
+	defer cancel()
+
+	cache.Add(1, "one")
+	cache.AddWithTTL(2, "two", time.Minute)
+
+	value, ok := cache.Get(2)
+	assert.Equal(t, "two", value)
+	assert.Equal(t, true, ok)
+
+	value, ok = cache.Get(3)
+	assert.Equal(t, "", value)
+	assert.Equal(t, false, ok)
+}
@@ -1,173 +1,76 @@
 package lrucache
 
-import (
-	"container/heap"
-	"container/list"
-	"time"
-)
-
-type CacheWithTTL2 struct {
-	Cache
-	expQueue expirationQueue
-}
+import "time"
 
-func NewWithTTL2(cap int) *CacheWithTTL2 {
-	return &CacheWithTTL2{
-		Cache: Cache{
-			cap:   cap,
-			data:  make(map[string]*list.Element, cap),
-			queue: list.New(),
-		},
-		expQueue: newExpirationQueue(),
-	}
+type CacheWithTTL2[K comparable, V any] struct {
+	Cache[K, V]
 }
 
-func (c *CacheWithTTL2) UpdateExpirations() {
-	if c.expQueue.Len() == 0 {
-		return
-	}
-
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// check and remove expired elements
-	for c.expQueue.Len() > 0 {
-		last := c.expQueue[0]
-
-		if last.Value.(*Element).expiresAt.Before(time.Now()) {
-			delete(c.data, last.Value.(*Element).key)
-			c.queue.Remove(last)
-			heap.Pop(&c.expQueue)
-		} else {
-			break
-		}
-	}
-}
+// NewWithTTL2 creates a cache of cap elements that removes expired elements
+// on access. Like New, it panics if cap is not positive.
+func NewWithTTL2[K comparable, V any](cap int, opts ...Option[K, V]) *CacheWithTTL2[K, V] {
+	cache := &CacheWithTTL2[K, V]{}
+	cache.init(cap, opts)
 
-func (c *CacheWithTTL2) Cap() int {
-	return c.cap
+	return cache
 }
 
-func (c *CacheWithTTL2) Len() int {
+func (c *CacheWithTTL2[K, V]) UpdateExpirations() {
+	// take the write lock only if there is something to remove
 	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+	expired := c.expQueue.due(c.clock.Now())
+	c.mutex.RUnlock()
 
-	return len(c.data)
-}
+	if !expired {
+		return
+	}
 
-func (c *CacheWithTTL2) Clear() {
+	// check and remove expired elements
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	defer c.unlock()
 
-	c.data = make(map[string]*list.Element, c.cap)
-	c.queue = list.New()
-	c.expQueue = newExpirationQueue()
+	c.removeExpired(c.clock.Now())
 }
 
-func (c *CacheWithTTL2) Add(key string, value any) {
+func (c *CacheWithTTL2[K, V]) Add(key K, value V) {
 	c.UpdateExpirations()
 
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// if element already exists just update element position in queue
-	if elem, ok := c.data[key]; ok {
-		elem.Value = &Element{
-			key:   key,
-			value: value,
-		}
-		c.queue.MoveToFront(elem)
-		return
-	}
-
-	// if cache is full displace the value that was not requested the most
-	if c.queue.Len() == c.cap {
-		last := c.queue.Back()
-		delete(c.data, last.Value.(*Element).key)
-		c.queue.Remove(last)
-	}
-
-	// add new element
-	newElem := c.queue.PushFront(&Element{
-		key:           key,
-		value:         value,
-		expQueueIndex: -1,
-	})
-	c.data[key] = newElem
+	c.Cache.Add(key, value)
 }
 
-func (c *CacheWithTTL2) AddWithTTL(key string, value interface{}, ttl time.Duration) {
+func (c *CacheWithTTL2[K, V]) AddWithTTL(key K, value V, ttl time.Duration) {
 	c.UpdateExpirations()
 
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	// update element if it already exists
-	if elem, ok := c.data[key]; ok {
-		elem.Value.(*Element).expiresAt = time.Now().Add(ttl)
-
-		if elem.Value.(*Element).expQueueIndex == -1 {
-			c.expQueue.Push(elem)
-		} else {
-			heap.Fix(&c.expQueue, elem.Value.(*Element).expQueueIndex)
-		}
-
-		c.queue.MoveToFront(elem)
-		return
-	}
+	defer c.unlock()
 
-	// if cache is full displace the value that was not requested the most
-	if c.queue.Len() == c.cap {
-		last := c.queue.Back()
-		delete(c.data, last.Value.(*Element).key)
-		c.queue.Remove(last)
+	if elem := c.add(key, value); elem != nil {
+		c.setTTL(elem, ttl)
 	}
-
-	// add new element
-	newElem := c.queue.PushFront(&Element{
-		key:           key,
-		value:         value,
-		expiresAt:     time.Now().Add(ttl),
-		expQueueIndex: -1,
-	})
-	c.data[key] = newElem
-	c.expQueue.Push(newElem)
 }
 
-func (c *CacheWithTTL2) Get(key string) (any, bool) {
+func (c *CacheWithTTL2[K, V]) Replace(key K, value V) bool {
 	c.UpdateExpirations()
 
-	c.mutex.RLock()
-	elem, ok := c.data[key]
-	c.mutex.RUnlock()
-
-	// update position in queue if element exists
-	if ok {
-		c.mutex.Lock()
-		defer c.mutex.Unlock()
+	return c.Cache.Replace(key, value)
+}
 
-		c.queue.MoveToFront(elem)
-		return elem.Value.(*Element).value, true
-	}
+func (c *CacheWithTTL2[K, V]) Get(key K) (V, bool) {
+	c.UpdateExpirations()
 
-	return nil, false
+	return c.Cache.Get(key)
 }
 
-func (c *CacheWithTTL2) Remove(key string) {
+func (c *CacheWithTTL2[K, V]) Remove(key K) {
 	c.UpdateExpirations()
 
-	c.mutex.RLock()
-	elem, ok := c.data[key]
-	c.mutex.RUnlock()
+	c.Cache.Remove(key)
+}
 
-	if ok {
-		c.mutex.Lock()
-		defer c.mutex.Unlock()
+// TTL returns the time left until the element expires, or NoExpiration if the
+// element was added without TTL. ok is false if there is no such element.
+func (c *CacheWithTTL2[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	c.UpdateExpirations()
 
-		if elem.Value.(*Element).expQueueIndex != -1 {
-			heap.Remove(&c.expQueue, elem.Value.(*Element).expQueueIndex)
-		}
-		c.queue.Remove(elem)
-		delete(c.data, key)
-	}
+	return c.ttl(key)
 }
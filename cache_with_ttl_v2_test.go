@@ -9,7 +9,7 @@ import (
 )
 
 func BenchmarkCacheTTL2_Add(b *testing.B) {
-	cache := NewWithTTL2(1000)
+	cache := NewStringWithTTL2(1000)
 
 	for i := 0; i < b.N; i++ {
 		cache.Add(strconv.Itoa(i), i)
@@ -17,7 +17,7 @@ func BenchmarkCacheTTL2_Add(b *testing.B) {
 }
 
 func BenchmarkCacheTTL2_Get(b *testing.B) {
-	cache := NewWithTTL2(1000)
+	cache := NewStringWithTTL2(1000)
 
 	for i := 0; i < 1000; i++ {
 		cache.Add(strconv.Itoa(i), i)
@@ -30,7 +30,7 @@ func BenchmarkCacheTTL2_Get(b *testing.B) {
 
 func Test_NewTTL2(t *testing.T) {
 	capacity := 5
-	cache := NewWithTTL2(capacity)
+	cache := NewStringWithTTL2(capacity)
 
 	require.NotNil(t, cache)
 	assert.NotNil(t, cache.data)
@@ -40,7 +40,8 @@ func Test_NewTTL2(t *testing.T) {
 
 func Test_NewTTL2_AddWithExpiration(t *testing.T) {
 	capacity := 4
-	cache := NewWithTTL2(capacity)
+	clock := NewFakeClock(time.Now())
+	cache := NewStringWithTTL2(capacity, WithClock[string, any](clock))
 
 	cache.Add("first", "value")
 	cache.AddWithTTL("first", "another value", time.Second*3)
@@ -52,7 +53,7 @@ func Test_NewTTL2_AddWithExpiration(t *testing.T) {
 	assert.Equal(t, 4, cache.queue.Len())
 	assert.Equal(t, 3, cache.expQueue.Len())
 
-	time.Sleep(time.Second * 4)
+	clock.Advance(time.Second * 4)
 
 	cache.UpdateExpirations()
 
@@ -63,14 +64,14 @@ func Test_NewTTL2_AddWithExpiration(t *testing.T) {
 
 func Test_CacheTTL2_Cap(t *testing.T) {
 	capacity := 5
-	cache := NewWithTTL2(capacity)
+	cache := NewStringWithTTL2(capacity)
 
 	assert.Equal(t, capacity, cache.Cap())
 }
 
 func Test_CacheTTL2_Len(t *testing.T) {
 	capacity := 3
-	cache := NewWithTTL2(capacity)
+	cache := NewStringWithTTL2(capacity)
 
 	cases := []struct {
 		name        string
@@ -113,7 +114,7 @@ func Test_CacheTTL2_Len(t *testing.T) {
 }
 
 func Test_CacheTTL2_Add(t *testing.T) {
-	cache := NewWithTTL2(3)
+	cache := NewStringWithTTL2(3)
 
 	cases := []struct {
 		name        string
@@ -157,7 +158,7 @@ func Test_CacheTTL2_Add(t *testing.T) {
 }
 
 func Test_CacheTTL2_Update(t *testing.T) {
-	cache := NewWithTTL2(3)
+	cache := NewStringWithTTL2(3)
 
 	cache.Add("key", "value")
 	cache.Add("key", "another value")
@@ -172,7 +173,7 @@ func Test_CacheTTL2_Update(t *testing.T) {
 
 func Test_CacheTTL2_Clear(t *testing.T) {
 	capacity := 5
-	cache := NewWithTTL2(capacity)
+	cache := NewStringWithTTL2(capacity)
 
 	cache.Add("key", "value")
 
@@ -186,7 +187,7 @@ func Test_CacheTTL2_Clear(t *testing.T) {
 
 func Test_CacheTTL2_Get(t *testing.T) {
 	capacity := 3
-	cache := NewWithTTL2(capacity)
+	cache := NewStringWithTTL2(capacity)
 
 	cache.Add("first", 1)
 	cache.Add("second", struct{ n int }{2})
@@ -243,7 +244,7 @@ func Test_CacheTTL2_Get(t *testing.T) {
 
 func Test_CacheTTL2_Remove(t *testing.T) {
 	capacity := 3
-	cache := NewWithTTL2(capacity)
+	cache := NewStringWithTTL2(capacity)
 
 	cache.Add("first", 1)
 	cache.Add("second", 2)
@@ -280,3 +281,18 @@ func Test_CacheTTL2_Remove(t *testing.T) {
 		})
 	}
 }
+
+func Test_CacheTTL2_TypedKeys(t *testing.T) {
+	cache := NewWithTTL2[int, string](3)
+
+	cache.Add(1, "one")
+	cache.AddWithTTL(2, "two", time.Minute)
+
+	value, ok := cache.Get(2)
+	assert.Equal(t, "two", value)
+	assert.Equal(t, true, ok)
+
+	value, ok = cache.Get(3)
+	assert.Equal(t, "", value)
+	assert.Equal(t, false, ok)
+}
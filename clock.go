@@ -0,0 +1,104 @@
+package lrucache
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time to the cache, see WithClock.
+type Clock interface {
+	Now() time.Time
+	// After sends the time on the returned channel once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock of the time package, the default one.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// FakeClock is a Clock that only moves when told to. It lets tests of code
+// using the caches expire elements without waiting.
+type FakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewFakeClock returns a FakeClock that is set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// After returns a channel that receives the time once the clock is advanced
+// by d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), c: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.set(c.now.Add(d))
+}
+
+// Set moves the clock to now, which must not be before the current time.
+func (c *FakeClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.set(now)
+}
+
+func (c *FakeClock) set(now time.Time) {
+	c.now = now
+
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.c <- now
+	}
+	c.waiters = waiters
+}
+
+// Waiters returns the number of channels returned by After that have not
+// received the time yet. Tests can wait for the background goroutine of
+// CacheWithTTL to start waiting before advancing the clock.
+func (c *FakeClock) Waiters() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.waiters)
+}
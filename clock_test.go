@@ -0,0 +1,47 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// advance moves the clock forward by d. If the background goroutine of
+// CacheWithTTL waits for the clock, advance waits until it has removed the
+// expired elements and waits again.
+func advance(t *testing.T, clock *FakeClock, d time.Duration) {
+	t.Helper()
+
+	waiters := clock.Waiters()
+	clock.Advance(d)
+	if waiters > 0 {
+		require.Eventually(t, func() bool { return clock.Waiters() >= waiters }, time.Second, time.Microsecond*100)
+	}
+}
+
+func Test_FakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	first := clock.After(time.Second)
+	second := clock.After(time.Minute)
+	assert.Equal(t, 2, clock.Waiters())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-first)
+	assert.Equal(t, 1, clock.Waiters())
+	select {
+	case <-second:
+		t.Fatal("second fired early")
+	default:
+	}
+
+	clock.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), <-second)
+	assert.Equal(t, 0, clock.Waiters())
+
+	// non-positive durations fire right away
+	assert.Equal(t, start.Add(time.Hour), <-clock.After(0))
+}
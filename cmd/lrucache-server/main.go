@@ -0,0 +1,239 @@
+// Command lrucache-server serves an LRU cache over HTTP and, optionally,
+// gRPC and the Redis and memcached protocols.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/bemmanue/LRUCacheService/httpapi"
+	"github.com/bemmanue/LRUCacheService/memcache"
+	"github.com/bemmanue/LRUCacheService/metrics"
+	"github.com/bemmanue/LRUCacheService/resp"
+)
+
+type config struct {
+	httpAddr        string
+	respAddr        string
+	memcacheAddr    string
+	grpcAddr        string
+	capacity        int
+	impl            string
+	shards          int
+	policy          string
+	tinyLFU         bool
+	timingWheel     bool
+	gcInterval      time.Duration
+	defaultTTL      time.Duration
+	ttlRules        []lrucache.TTLRule
+	ttlJitter       float64
+	shutdownTimeout time.Duration
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	collector := metrics.NewCollector()
+
+	cache, err := newCache[[]byte](cfg, collector, "default")
+	if err != nil {
+		return err
+	}
+	defer cache.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", collector)
+	mux.Handle("/", httpapi.New(cache))
+
+	services := []service{newHTTPService(cfg.httpAddr, mux)}
+	if cfg.respAddr != "" {
+		svc, err := newRESPService(cfg.respAddr, resp.NewServer(cache))
+		if err != nil {
+			return err
+		}
+		services = append(services, svc)
+	}
+	if cfg.grpcAddr != "" {
+		svc, err := newGRPCService(cfg.grpcAddr, cache)
+		if err != nil {
+			return err
+		}
+		services = append(services, svc)
+	}
+	if cfg.memcacheAddr != "" {
+		// memcached items carry flags and CAS values, so they are kept in a separate cache
+		items, err := newCache[memcache.Item](cfg, collector, "memcache")
+		if err != nil {
+			return err
+		}
+		defer items.Close()
+
+		svc, err := newMemcacheService(cfg.memcacheAddr, memcache.NewServer(items))
+		if err != nil {
+			return err
+		}
+		services = append(services, svc)
+	}
+
+	errCh := make(chan error, len(services))
+	for _, svc := range services {
+		svc := svc
+		go func() {
+			log.Printf("%s: listening on %s", svc.name, svc.addr)
+			if err := svc.serve(); err != nil {
+				errCh <- fmt.Errorf("%s: %w", svc.name, err)
+			}
+		}()
+	}
+
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+	}
+
+	log.Print("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdownTimeout)
+	defer cancel()
+
+	for _, svc := range services {
+		if stopErr := svc.stop(shutdownCtx); stopErr != nil && err == nil {
+			err = fmt.Errorf("%s: %w", svc.name, stopErr)
+		}
+	}
+
+	return err
+}
+
+func parseFlags(args []string) (config, error) {
+	var cfg config
+
+	fs := flag.NewFlagSet("lrucache-server", flag.ContinueOnError)
+	fs.StringVar(&cfg.httpAddr, "http-addr", ":8080", "address of the HTTP API")
+	fs.StringVar(&cfg.respAddr, "resp-addr", "", "address of the Redis protocol listener, disabled if empty")
+	fs.StringVar(&cfg.memcacheAddr, "memcache-addr", "", "address of the memcached protocol listener, disabled if empty")
+	fs.StringVar(&cfg.grpcAddr, "grpc-addr", "", "address of the gRPC listener, disabled if empty")
+	fs.IntVar(&cfg.capacity, "cap", 1024, "maximum number of cached keys")
+	fs.StringVar(&cfg.impl, "impl", "ttl2", "cache implementation: ttl (background GC), ttl2 (lazy expiration) or sharded")
+	fs.IntVar(&cfg.shards, "shards", 0, "number of shards of the sharded implementation, 0 to pick by the number of CPUs")
+	fs.StringVar(&cfg.policy, "policy", "lru", "eviction policy: lru, lfu, slru, 2q, arc, clock or s3fifo")
+	fs.BoolVar(&cfg.timingWheel, "timing-wheel", false, "keep track of expirations in a timing wheel instead of a heap")
+	fs.BoolVar(&cfg.tinyLFU, "tinylfu", false, "admit new keys to the full cache only if they are used more often than the evicted ones")
+	fs.DurationVar(&cfg.gcInterval, "gc-interval", time.Second, "expiration check interval of the ttl implementation")
+	fs.DurationVar(&cfg.defaultTTL, "default-ttl", 0, "TTL of keys set without one, 0 to keep them until evicted")
+	fs.Float64Var(&cfg.ttlJitter, "ttl-jitter", 0, "fraction of every TTL to randomly add or subtract, such as 0.1 for ±10%")
+	fs.Func("ttl-rule", "`prefix=ttl` overriding the default TTL for keys with the prefix, ttl 0 keeps them until evicted; can be repeated", func(s string) error {
+		rule, err := parseTTLRule(s)
+		if err != nil {
+			return err
+		}
+		cfg.ttlRules = append(cfg.ttlRules, rule)
+		return nil
+	})
+	fs.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 10*time.Second, "time to wait for in-flight requests on shutdown")
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if cfg.capacity <= 0 {
+		return cfg, fmt.Errorf("cap must be positive, got %d", cfg.capacity)
+	}
+
+	return cfg, nil
+}
+
+// parseTTLRule parses a rule of the -ttl-rule flag. The prefix can contain
+// "=", so the TTL follows the last one.
+func parseTTLRule(s string) (lrucache.TTLRule, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return lrucache.TTLRule{}, fmt.Errorf("%q is not prefix=ttl", s)
+	}
+
+	ttl, err := time.ParseDuration(s[i+1:])
+	if err != nil {
+		return lrucache.TTLRule{}, err
+	}
+	if ttl == 0 {
+		ttl = lrucache.NoExpiration
+	}
+
+	return lrucache.TTLRule{Prefix: s[:i], TTL: ttl}, nil
+}
+
+// newCache creates a cache of the configured implementation and registers it
+// in collector under name.
+func newCache[V any](cfg config, collector *metrics.Collector, name string) (lrucache.ICacheWithTTL[string, V], error) {
+	policy, err := newPolicy[V](cfg.policy)
+	if err != nil {
+		return nil, err
+	}
+	opts := []lrucache.Option[string, V]{
+		lrucache.WithCapacity[string, V](cfg.capacity),
+		lrucache.WithEvictionPolicy(policy),
+		lrucache.WithMetrics[string, V](collector, name),
+		lrucache.WithDefaultTTL[string, V](cfg.defaultTTL),
+		lrucache.WithTTLRules[V](cfg.ttlRules...),
+		lrucache.WithTTLJitter[string, V](cfg.ttlJitter),
+	}
+	if cfg.tinyLFU {
+		opts = append(opts, lrucache.WithTinyLFU[string, V]())
+	}
+	if cfg.timingWheel {
+		opts = append(opts, lrucache.WithTimingWheel[string, V]())
+	}
+
+	switch cfg.impl {
+	case "ttl":
+		opts = append(opts, lrucache.WithBackgroundExpiration[string, V](cfg.gcInterval))
+	case "ttl2":
+	case "sharded":
+		opts = append(opts, lrucache.WithShards[string, V](cfg.shards))
+	default:
+		return nil, fmt.Errorf("unknown cache implementation %q", cfg.impl)
+	}
+
+	return lrucache.NewCache(opts...)
+}
+
+func newPolicy[V any](name string) (func(cap int) lrucache.EvictionPolicy[string, V], error) {
+	switch name {
+	case "lru":
+		return lrucache.NewLRUPolicy[string, V], nil
+	case "lfu":
+		return lrucache.NewLFUPolicy[string, V], nil
+	case "slru":
+		return lrucache.NewSLRUPolicy[string, V], nil
+	case "2q":
+		return lrucache.NewTwoQueuePolicy[string, V], nil
+	case "arc":
+		return lrucache.NewARCPolicy[string, V], nil
+	case "clock":
+		return lrucache.NewCLOCKPolicy[string, V], nil
+	case "s3fifo":
+		return lrucache.NewS3FIFOPolicy[string, V], nil
+	default:
+		return nil, fmt.Errorf("unknown eviction policy %q", name)
+	}
+}
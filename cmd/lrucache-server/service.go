@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/bemmanue/LRUCacheService/grpcapi"
+	"github.com/bemmanue/LRUCacheService/memcache"
+	"github.com/bemmanue/LRUCacheService/resp"
+	"google.golang.org/grpc"
+)
+
+// service is a listener of the server that can be stopped on shutdown.
+// serve blocks until the service is stopped and returns nil in that case.
+type service struct {
+	name  string
+	addr  string
+	serve func() error
+	stop  func(ctx context.Context) error
+}
+
+func newHTTPService(addr string, handler http.Handler) service {
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+
+	return service{
+		name: "http",
+		addr: addr,
+		serve: func() error {
+			if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		stop: srv.Shutdown,
+	}
+}
+
+func newRESPService(addr string, srv *resp.Server) (service, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return service{}, err
+	}
+
+	return service{
+		name: "resp",
+		addr: l.Addr().String(),
+		serve: func() error {
+			if err := srv.Serve(l); !errors.Is(err, resp.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		stop: func(context.Context) error {
+			return srv.Close()
+		},
+	}, nil
+}
+
+func newMemcacheService(addr string, srv *memcache.Server) (service, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return service{}, err
+	}
+
+	return service{
+		name: "memcache",
+		addr: l.Addr().String(),
+		serve: func() error {
+			if err := srv.Serve(l); !errors.Is(err, memcache.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		stop: func(context.Context) error {
+			return srv.Close()
+		},
+	}, nil
+}
+
+func newGRPCService(addr string, cache lrucache.ICacheWithTTL[string, []byte]) (service, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return service{}, err
+	}
+
+	srv := grpc.NewServer()
+	grpcapi.Register(srv, cache)
+
+	return service{
+		name: "grpc",
+		addr: l.Addr().String(),
+		serve: func() error {
+			if err := srv.Serve(l); !errors.Is(err, grpc.ErrServerStopped) {
+				return err
+			}
+			return nil
+		},
+		stop: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				return ctx.Err()
+			}
+		},
+	}, nil
+}
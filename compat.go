@@ -0,0 +1,26 @@
+package lrucache
+
+import (
+	"context"
+	"time"
+)
+
+// StringCache, StringCacheWithTTL and StringCacheWithTTL2 keep the
+// pre-generics shape of the caches: string keys and untyped values.
+type (
+	StringCache         = Cache[string, any]
+	StringCacheWithTTL  = CacheWithTTL[string, any]
+	StringCacheWithTTL2 = CacheWithTTL2[string, any]
+)
+
+func NewString(cap int, opts ...Option[string, any]) *StringCache {
+	return New[string, any](cap, opts...)
+}
+
+func NewStringWithTTL(cap int, expCheck time.Duration, opts ...Option[string, any]) (*StringCacheWithTTL, context.CancelFunc) {
+	return NewWithTTL[string, any](cap, expCheck, opts...)
+}
+
+func NewStringWithTTL2(cap int, opts ...Option[string, any]) *StringCacheWithTTL2 {
+	return NewWithTTL2[string, any](cap, opts...)
+}
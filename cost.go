@@ -0,0 +1,96 @@
+package lrucache
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTooLarge is returned when the cost of a value exceeds the maximum
+	// cost of the cache.
+	ErrTooLarge = errors.New("lrucache: value cost exceeds the maximum cost")
+	// ErrNotAdmitted is returned when the admission filter set with
+	// WithTinyLFU keeps a new key out of the full cache.
+	ErrNotAdmitted = errors.New("lrucache: key not admitted")
+)
+
+// Sizer returns the cost of a value, for example its size in bytes.
+type Sizer[K comparable, V any] func(key K, value V) int64
+
+// costOf returns the cost of a value added without an explicit cost.
+func (c *Cache[K, V]) costOf(key K, value V) int64 {
+	if c.sizer == nil {
+		return 1
+	}
+	return c.sizer(key, value)
+}
+
+// MaxCost returns the maximum total cost of the elements, or 0 if only their
+// number is limited.
+func (c *Cache[K, V]) MaxCost() int64 {
+	return c.maxCost
+}
+
+// Cost returns the total cost of the elements.
+func (c *Cache[K, V]) Cost() int64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.usedCost
+}
+
+// AddWithCost adds the value with the given cost instead of the one returned
+// by the Sizer. Caches with TTL apply the default TTL or the TTL of the
+// matching prefix rule, Cache stores the value without expiration. Elements
+// are evicted until the value fits. If it doesn't fit even in the empty cache, ErrTooLarge is returned
+// and the old value of the key, if any, is removed. Add and AddWithTTL reject
+// such values silently, counting them in Stats().Rejections.
+func (c *Cache[K, V]) AddWithCost(key K, value V, cost int64) error {
+	c.mutex.Lock()
+	defer c.unlock()
+
+	elem, err := c.put(key, value, nil, cost)
+	if err != nil {
+		return err
+	}
+	c.setDefaultTTL(elem)
+	return nil
+}
+
+// AddWithTTLAndCost adds the value with ttl and the given cost, see
+// AddWithCost.
+func (c *CacheWithTTL[K, V]) AddWithTTLAndCost(key K, value V, ttl time.Duration, cost int64) error {
+	c.mutex.Lock()
+	defer c.unlock()
+
+	elem, err := c.put(key, value, nil, cost)
+	if err != nil {
+		return err
+	}
+	c.setTTL(elem, ttl)
+	return nil
+}
+
+// AddWithCost adds the value with the default TTL and the given cost, see
+// Cache.AddWithCost.
+func (c *CacheWithTTL2[K, V]) AddWithCost(key K, value V, cost int64) error {
+	c.UpdateExpirations()
+
+	return c.Cache.AddWithCost(key, value, cost)
+}
+
+// AddWithTTLAndCost adds the value with ttl and the given cost, see
+// Cache.AddWithCost.
+func (c *CacheWithTTL2[K, V]) AddWithTTLAndCost(key K, value V, ttl time.Duration, cost int64) error {
+	c.UpdateExpirations()
+
+	c.mutex.Lock()
+	defer c.unlock()
+
+	elem, err := c.put(key, value, nil, cost)
+	if err != nil {
+		return err
+	}
+	c.setTTL(elem, ttl)
+	return nil
+}
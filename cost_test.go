@@ -0,0 +1,181 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func lenSizer(_ string, value string) int64 {
+	return int64(len(value))
+}
+
+func Test_MaxCost(t *testing.T) {
+	var evicted []string
+	cache := New[string, string](100,
+		WithMaxCost[string, string](10),
+		WithSizer[string, string](lenSizer),
+		WithOnEvict(func(key string, _ string, reason EvictReason) {
+			if reason == EvictReasonCapacity {
+				evicted = append(evicted, key)
+			}
+		}),
+	)
+
+	cache.Add("first", "aaaa")
+	cache.Add("second", "bbbb")
+	cache.Add("third", "cc")
+	assert.Equal(t, int64(10), cache.Cost())
+	assert.Equal(t, int64(10), cache.MaxCost())
+
+	// the back of the queue is evicted until the value fits
+	cache.Get("first")
+	cache.Add("forth", "dddddd")
+	assert.Equal(t, []string{"second", "third"}, evicted)
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, int64(10), cache.Cost())
+
+	cache.Remove("first")
+	assert.Equal(t, int64(6), cache.Cost())
+
+	// Add rejects values that are too large silently, but the old value is
+	// removed all the same
+	cache.Add("forth", "eeeeeeeeeee")
+	_, ok := cache.Get("forth")
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), cache.Cost())
+
+	cache.Clear()
+	assert.Equal(t, int64(0), cache.Cost())
+}
+
+func Test_AddWithCost(t *testing.T) {
+	cache := New[string, string](100, WithMaxCost[string, string](10))
+
+	require.NoError(t, cache.AddWithCost("first", "a", 4))
+	require.NoError(t, cache.AddWithCost("second", "b", 4))
+	assert.Equal(t, int64(8), cache.Cost())
+
+	// values costing more than the maximum are rejected, and the old value
+	// of an updated key is removed so that it isn't read after the update
+	assert.ErrorIs(t, cache.AddWithCost("third", "c", 11), ErrTooLarge)
+	assert.ErrorIs(t, cache.AddWithCost("first", "c", 11), ErrTooLarge)
+	_, ok := cache.Get("first")
+	assert.False(t, ok)
+	assert.Equal(t, int64(4), cache.Cost())
+	assert.Equal(t, uint64(2), cache.Stats().Rejections)
+
+	// a growing element evicts others, but not itself
+	require.NoError(t, cache.AddWithCost("first", "a", 4))
+	cache.Get("second")
+	require.NoError(t, cache.AddWithCost("first", "d", 10))
+	_, ok = cache.Get("second")
+	assert.False(t, ok)
+	value, ok := cache.Get("first")
+	assert.True(t, ok)
+	assert.Equal(t, "d", value)
+	assert.Equal(t, int64(10), cache.Cost())
+
+	// without a sizer Add costs 1
+	cache.Add("third", "c")
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, int64(1), cache.Cost())
+}
+
+func Test_MaxCost_Cap(t *testing.T) {
+	cache := New[string, string](2, WithMaxCost[string, string](100))
+
+	require.NoError(t, cache.AddWithCost("first", "a", 1))
+	require.NoError(t, cache.AddWithCost("second", "b", 1))
+	require.NoError(t, cache.AddWithCost("third", "c", 1))
+
+	// the number of elements is still limited
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, int64(2), cache.Cost())
+}
+
+func Test_AddWithTTLAndCost(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	ttlCache, cancel := NewWithTTL[string, string](10, time.Millisecond*5, WithMaxCost[string, string](10), WithClock[string, string](clock))
+	defer cancel()
+	require.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Microsecond*100)
+
+	for name, cache := range map[string]interface {
+		AddWithTTLAndCost(key string, value string, ttl time.Duration, cost int64) error
+		Get(key string) (string, bool)
+		Cost() int64
+	}{
+		"CacheWithTTL":  ttlCache,
+		"CacheWithTTL2": NewWithTTL2[string, string](10, WithMaxCost[string, string](10), WithClock[string, string](clock)),
+		"ShardedCache":  NewSharded[string, string](10, WithShards[string, string](1), WithMaxCost[string, string](10), WithClock[string, string](clock)),
+	} {
+		cache := cache
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, cache.AddWithTTLAndCost("first", "a", time.Millisecond*20, 6))
+			assert.ErrorIs(t, cache.AddWithTTLAndCost("second", "b", time.Minute, 11), ErrTooLarge)
+			assert.Equal(t, int64(6), cache.Cost())
+
+			advance(t, clock, time.Millisecond*30)
+			_, ok := cache.Get("first")
+			assert.False(t, ok)
+			assert.Equal(t, int64(0), cache.Cost())
+		})
+	}
+}
+
+func Test_NewSharded_MaxCost(t *testing.T) {
+	cache := NewSharded[string, string](10, WithShards[string, string](3), WithMaxCost[string, string](10))
+
+	assert.Equal(t, int64(10), cache.MaxCost())
+	assert.Equal(t, int64(4), cache.shards[0].MaxCost())
+	assert.Equal(t, int64(3), cache.shards[1].MaxCost())
+	assert.Equal(t, int64(3), cache.shards[2].MaxCost())
+}
+
+func Test_NewSharded_MaxCostBelowShards(t *testing.T) {
+	cache := NewSharded[string, string](100, WithShards[string, string](8), WithMaxCost[string, string](3))
+
+	assert.Equal(t, 3, cache.Shards())
+	for _, shard := range cache.shards {
+		assert.Equal(t, int64(1), shard.MaxCost())
+	}
+
+	for i := 0; i < 100; i++ {
+		cache.Add(strconv.Itoa(i), "value")
+	}
+	assert.LessOrEqual(t, cache.Cost(), int64(3))
+}
+
+func Test_MaxCost_Policies(t *testing.T) {
+	for _, f := range policyFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			cache := New[string, int](50, WithEvictionPolicy(f.new), WithMaxCost[string, int](100))
+			rnd := rand.New(rand.NewSource(1))
+
+			for i := 0; i < 20000; i++ {
+				key := strconv.Itoa(rnd.Intn(200))
+				if rnd.Intn(3) == 0 {
+					cache.Get(key)
+				} else {
+					_ = cache.AddWithCost(key, i, int64(rnd.Intn(30)))
+				}
+
+				require.LessOrEqual(t, cache.Cost(), int64(100))
+			}
+
+			cache.mutex.Lock()
+			cache.drainReads()
+			var cost int64
+			for _, elem := range cache.data {
+				cost += elem.cost
+			}
+			assert.Equal(t, cost, cache.usedCost)
+			assert.Equal(t, len(cache.data), cache.queue.Len())
+			cache.mutex.Unlock()
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package lrucache
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// TTLRule sets the TTL of keys that start with Prefix, see WithTTLRules.
+type TTLRule struct {
+	Prefix string
+	TTL    time.Duration
+}
+
+// WithDefaultTTL sets the TTL that Add, AddWithCost and GetOrLoad apply in
+// caches with TTL when no TTL is given. NoExpiration or 0 keeps such values
+// until they are evicted, which is the default. Cache, which doesn't expire
+// elements, ignores it.
+func WithDefaultTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.defaultTTL = ttl
+	}
+}
+
+// WithTTLRules overrides the default TTL for keys with the given prefixes.
+// The rule with the longest matching prefix wins, its TTL can be
+// NoExpiration. Like WithDefaultTTL, it is ignored by Cache.
+func WithTTLRules[V any](rules ...TTLRule) Option[string, V] {
+	sorted := make([]TTLRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Prefix) > len(sorted[j].Prefix)
+	})
+
+	return func(o *options[string, V]) {
+		o.ttlRules = rules
+		o.ttlRule = func(key string) (time.Duration, bool) {
+			for _, rule := range sorted {
+				if strings.HasPrefix(key, rule.Prefix) {
+					return rule.TTL, true
+				}
+			}
+			return 0, false
+		}
+	}
+}
+
+// validTTL reports whether ttl can be used as a default TTL.
+func validTTL(ttl time.Duration) bool {
+	return ttl > 0 || ttl == NoExpiration
+}
+
+// setDefaultTTL applies the TTL of the key's rule or the default TTL to elem.
+// Must be called with the write lock held.
+func (c *Cache[K, V]) setDefaultTTL(elem *Element[K, V]) {
+	ttl := c.defaultTTL
+	if c.ttlRule != nil {
+		if ruleTTL, ok := c.ttlRule(elem.key); ok {
+			ttl = ruleTTL
+		}
+	}
+
+	if ttl > 0 {
+		c.setTTL(elem, ttl)
+	} else {
+		c.removeExpiration(elem)
+	}
+}
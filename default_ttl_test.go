@@ -0,0 +1,123 @@
+package lrucache
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_DefaultTTL(t *testing.T) {
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 10, WithDefaultTTL[string, any](time.Minute), WithClock[string, any](clock))
+
+			cache.Add("first", 1)
+			cache.AddWithTTL("second", 2, time.Hour)
+			cache.AddWithTTL("third", 3, NoExpiration)
+
+			ttl, ok := cache.TTL("first")
+			assert.True(t, ok)
+			assert.Equal(t, time.Minute, ttl)
+
+			ttl, ok = cache.TTL("third")
+			assert.True(t, ok)
+			assert.Equal(t, NoExpiration, ttl)
+
+			// Add of an existing key applies the default TTL as well
+			cache.Add("third", 3)
+			ttl, _ = cache.TTL("third")
+			assert.Equal(t, time.Minute, ttl)
+
+			advance(t, clock, time.Minute*2)
+			_, ok = cache.Get("first")
+			assert.False(t, ok)
+			_, ok = cache.Get("third")
+			assert.False(t, ok)
+			_, ok = cache.Get("second")
+			assert.True(t, ok)
+		})
+	}
+}
+
+func Test_DefaultTTL_Cache(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := New[string, int](10,
+		WithDefaultTTL[string, int](time.Minute),
+		WithTTLRules[int](TTLRule{Prefix: "user:", TTL: time.Minute}),
+		WithClock[string, int](clock),
+	)
+
+	// Cache doesn't expire elements, so the options are ignored
+	cache.Add("first", 1)
+	cache.Add("user:1", 2)
+	clock.Advance(time.Hour)
+	require.NoError(t, cache.Resize(5))
+
+	_, ok := cache.Get("first")
+	assert.True(t, ok)
+	_, ok = cache.Get("user:1")
+	assert.True(t, ok)
+}
+
+func Test_TTLRules(t *testing.T) {
+	cache := NewWithTTL2[string, int](10,
+		WithDefaultTTL[string, int](time.Minute),
+		WithTTLRules[int](
+			TTLRule{Prefix: "user:", TTL: time.Minute * 5},
+			TTLRule{Prefix: "user:admin:", TTL: NoExpiration},
+			TTLRule{Prefix: "session:", TTL: time.Minute * 30},
+		),
+		WithClock[string, int](NewFakeClock(time.Now())),
+	)
+
+	tests := map[string]time.Duration{
+		"other":         time.Minute,
+		"user:1":        time.Minute * 5,
+		"user:admin:1":  NoExpiration,
+		"session:1":     time.Minute * 30,
+		"users:1":       time.Minute,
+		"prefix:user:1": time.Minute,
+	}
+	for key, want := range tests {
+		cache.Add(key, 1)
+		ttl, ok := cache.TTL(key)
+		assert.True(t, ok)
+		assert.Equal(t, want, ttl, key)
+	}
+
+	// explicit TTL wins over the rules
+	cache.AddWithTTL("user:2", 2, time.Hour)
+	ttl, _ := cache.TTL("user:2")
+	assert.Equal(t, time.Hour, ttl)
+}
+
+func Test_DefaultTTL_Loader(t *testing.T) {
+	cache := NewWithTTL2[string, int](10,
+		WithDefaultTTL[string, int](time.Minute),
+		WithClock[string, int](NewFakeClock(time.Now())),
+	)
+
+	loadWithTTL := func(ttl time.Duration) Loader[string, int] {
+		return func(ctx context.Context, key string) (int, time.Duration, error) {
+			return 1, ttl, nil
+		}
+	}
+
+	_, err := cache.GetOrLoad(context.Background(), "default", loadWithTTL(0))
+	require.NoError(t, err)
+	_, err = cache.GetOrLoad(context.Background(), "never", loadWithTTL(NoExpiration))
+	require.NoError(t, err)
+	_, err = cache.GetOrLoad(context.Background(), "own", loadWithTTL(time.Hour))
+	require.NoError(t, err)
+
+	ttl, _ := cache.TTL("default")
+	assert.Equal(t, time.Minute, ttl)
+	ttl, _ = cache.TTL("never")
+	assert.Equal(t, NoExpiration, ttl)
+	ttl, _ = cache.TTL("own")
+	assert.Equal(t, time.Hour, ttl)
+}
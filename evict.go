@@ -0,0 +1,62 @@
+package lrucache
+
+// EvictReason tells why an element left the cache.
+type EvictReason int
+
+const (
+	// EvictReasonCapacity means the element was displaced by a new one
+	// because the cache was full.
+	EvictReasonCapacity EvictReason = iota
+	// EvictReasonExpired means the TTL of the element has passed.
+	EvictReasonExpired
+	// EvictReasonRemoved means the element was removed with Remove.
+	EvictReasonRemoved
+	// EvictReasonReplaced means the value was overwritten by Add or AddWithTTL
+	// with the same key. The hook receives the old value.
+	EvictReasonReplaced
+	// EvictReasonCleared means the element was dropped by Clear.
+	EvictReasonCleared
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictReasonCapacity:
+		return "capacity"
+	case EvictReasonExpired:
+		return "expired"
+	case EvictReasonRemoved:
+		return "removed"
+	case EvictReasonReplaced:
+		return "replaced"
+	case EvictReasonCleared:
+		return "cleared"
+	default:
+		return "unknown"
+	}
+}
+
+type eviction[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
+
+// notify queues an eviction for the OnEvict hook.
+// Must be called with the write lock held.
+func (c *Cache[K, V]) notify(key K, value V, reason EvictReason) {
+	if c.onEvict != nil {
+		c.evicted = append(c.evicted, eviction[K, V]{key: key, value: value, reason: reason})
+	}
+}
+
+// unlock releases the write lock and then calls the OnEvict hook for every
+// eviction queued while the lock was held, so the hook may use the cache.
+func (c *Cache[K, V]) unlock() {
+	evicted := c.evicted
+	c.evicted = nil
+	c.mutex.Unlock()
+
+	for _, e := range evicted {
+		c.onEvict(e.key, e.value, e.reason)
+	}
+}
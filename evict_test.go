@@ -0,0 +1,146 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type evictionRecorder struct {
+	mutex     sync.Mutex
+	evictions []eviction[string, any]
+}
+
+func (r *evictionRecorder) option() Option[string, any] {
+	return WithOnEvict(func(key string, value any, reason EvictReason) {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		r.evictions = append(r.evictions, eviction[string, any]{key: key, value: value, reason: reason})
+	})
+}
+
+func (r *evictionRecorder) get() []eviction[string, any] {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]eviction[string, any](nil), r.evictions...)
+}
+
+func Test_OnEvict(t *testing.T) {
+	for _, f := range cacheFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			t.Run("capacity", func(t *testing.T) {
+				var r evictionRecorder
+				cache := f.new(t, 2, r.option())
+
+				cache.Add("first", 1)
+				cache.Add("second", 2)
+				cache.Add("third", 3)
+
+				assert.Equal(t, []eviction[string, any]{
+					{key: "first", value: 1, reason: EvictReasonCapacity},
+				}, r.get())
+			})
+
+			t.Run("removed", func(t *testing.T) {
+				var r evictionRecorder
+				cache := f.new(t, 2, r.option())
+
+				cache.Add("first", 1)
+				cache.Remove("first")
+				cache.Remove("random key")
+
+				assert.Equal(t, []eviction[string, any]{
+					{key: "first", value: 1, reason: EvictReasonRemoved},
+				}, r.get())
+			})
+
+			t.Run("replaced", func(t *testing.T) {
+				var r evictionRecorder
+				cache := f.new(t, 2, r.option())
+
+				cache.Add("first", 1)
+				cache.Add("first", 2)
+
+				assert.Equal(t, []eviction[string, any]{
+					{key: "first", value: 1, reason: EvictReasonReplaced},
+				}, r.get())
+			})
+
+			t.Run("cleared", func(t *testing.T) {
+				var r evictionRecorder
+				cache := f.new(t, 2, r.option())
+
+				cache.Add("first", 1)
+				cache.Add("second", 2)
+				cache.Clear()
+
+				assert.ElementsMatch(t, []eviction[string, any]{
+					{key: "first", value: 1, reason: EvictReasonCleared},
+					{key: "second", value: 2, reason: EvictReasonCleared},
+				}, r.get())
+			})
+
+			t.Run("hook may use the cache", func(t *testing.T) {
+				var cache ICache[string, any]
+				cache = f.new(t, 1, WithOnEvict(func(key string, value any, reason EvictReason) {
+					if reason == EvictReasonCapacity {
+						cache.Len()
+						cache.Remove(key)
+					}
+				}))
+
+				cache.Add("first", 1)
+				cache.Add("second", 2)
+
+				assert.Equal(t, 1, cache.Len())
+			})
+		})
+	}
+
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			t.Run("expired", func(t *testing.T) {
+				var r evictionRecorder
+				clock := NewFakeClock(time.Now())
+				cache := f.new(t, 2, r.option(), WithClock[string, any](clock))
+
+				cache.AddWithTTL("first", 1, time.Millisecond*20)
+				cache.AddWithTTL("second", 2, time.Hour)
+
+				advance(t, clock, time.Millisecond*30)
+				_, ok := cache.Get("first")
+				assert.False(t, ok)
+
+				assert.Equal(t, []eviction[string, any]{
+					{key: "first", value: 1, reason: EvictReasonExpired},
+				}, r.get())
+			})
+
+			t.Run("replaced with ttl", func(t *testing.T) {
+				var r evictionRecorder
+				cache := f.new(t, 2, r.option())
+
+				cache.Add("first", 1)
+				cache.AddWithTTL("first", 2, time.Hour)
+
+				assert.Equal(t, []eviction[string, any]{
+					{key: "first", value: 1, reason: EvictReasonReplaced},
+				}, r.get())
+			})
+		})
+	}
+}
+
+func Test_EvictReason_String(t *testing.T) {
+	assert.Equal(t, "capacity", EvictReasonCapacity.String())
+	assert.Equal(t, "expired", EvictReasonExpired.String())
+	assert.Equal(t, "removed", EvictReasonRemoved.String())
+	assert.Equal(t, "replaced", EvictReasonReplaced.String())
+	assert.Equal(t, "cleared", EvictReasonCleared.String())
+	assert.Equal(t, "unknown", EvictReason(-1).String())
+}
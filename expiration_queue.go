@@ -2,42 +2,91 @@ package lrucache
 
 import (
 	"container/heap"
-	"container/list"
+	"time"
 )
 
-type expirationQueue []*list.Element
+// expirations keeps track of when elements expire. Element.expQueueIndex is
+// -1 for elements that are not scheduled. Methods are called with the write
+// lock held, except for due and Len that only need the read lock.
+type expirations[K comparable, V any] interface {
+	// schedule adds the element or moves it to its new expiresAt.
+	schedule(elem *Element[K, V])
+	// cancel removes the element if it is scheduled.
+	cancel(elem *Element[K, V])
+	// due reports whether elements that expired before now may be scheduled.
+	due(now time.Time) bool
+	// expire calls remove with the elements that expired before now,
+	// remove must cancel the element.
+	expire(now time.Time, remove func(elem *Element[K, V]))
+	// clear removes all elements.
+	clear()
+	Len() int
+}
+
+// expirationQueue is a binary heap of elements ordered by expiresAt, the
+// default expirations.
+type expirationQueue[K comparable, V any] []*Element[K, V]
 
-func newExpirationQueue() expirationQueue {
-	var q expirationQueue = make([]*list.Element, 0)
+func newExpirationQueue[K comparable, V any]() *expirationQueue[K, V] {
+	var q expirationQueue[K, V] = make([]*Element[K, V], 0)
 	heap.Init(&q)
-	return q
+	return &q
+}
+
+func (q *expirationQueue[K, V]) schedule(elem *Element[K, V]) {
+	if elem.expQueueIndex == -1 {
+		heap.Push(q, elem)
+	} else {
+		heap.Fix(q, elem.expQueueIndex)
+	}
+}
+
+func (q *expirationQueue[K, V]) cancel(elem *Element[K, V]) {
+	if index := elem.expQueueIndex; index != -1 {
+		heap.Remove(q, index)
+	}
+}
+
+func (q *expirationQueue[K, V]) due(now time.Time) bool {
+	return len(*q) > 0 && (*q)[0].expiresAt.Before(now)
+}
+
+func (q *expirationQueue[K, V]) expire(now time.Time, remove func(elem *Element[K, V])) {
+	for q.due(now) {
+		remove((*q)[0])
+	}
+}
+
+func (q *expirationQueue[K, V]) clear() {
+	*q = nil
 }
 
-func (q expirationQueue) Len() int {
+func (q expirationQueue[K, V]) Len() int {
 	return len(q)
 }
 
-func (q expirationQueue) Less(i, j int) bool {
-	return q[i].Value.(*Element).expiresAt.Before(q[j].Value.(*Element).expiresAt)
+func (q expirationQueue[K, V]) Less(i, j int) bool {
+	return q[i].expiresAt.Before(q[j].expiresAt)
 }
 
-func (q expirationQueue) Swap(i, j int) {
+func (q expirationQueue[K, V]) Swap(i, j int) {
 	q[i], q[j] = q[j], q[i]
-	q[i].Value.(*Element).expQueueIndex = i
-	q[j].Value.(*Element).expQueueIndex = j
+	q[i].expQueueIndex = i
+	q[j].expQueueIndex = j
 }
 
-func (q *expirationQueue) Push(x any) {
-	elem := x.(*list.Element)
-	elem.Value.(*Element).expQueueIndex = len(*q)
-	*q = append(*q, x.(*list.Element))
+func (q *expirationQueue[K, V]) Push(x any) {
+	elem := x.(*Element[K, V])
+	elem.expQueueIndex = len(*q)
+	*q = append(*q, elem)
 }
 
-func (q *expirationQueue) Pop() any {
+func (q *expirationQueue[K, V]) Pop() any {
 	old := *q
 	n := len(old)
 	x := old[n-1]
-	x.Value.(*Element).expQueueIndex = -1
+	x.expQueueIndex = -1
+	old[n-1] = nil
 	*q = old[0 : n-1]
 	return x
 }
@@ -0,0 +1,5 @@
+// Package cachepb contains the protobuf messages and the gRPC client and
+// server stubs generated from cache.proto.
+package cachepb
+
+//go:generate sh -c "cd ../.. && buf generate --path grpcapi/cachepb/cache.proto"
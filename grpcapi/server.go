@@ -0,0 +1,149 @@
+// Package grpcapi exposes a cache over gRPC. The service is defined in
+// cachepb/cache.proto, clients are created with cachepb.NewCacheClient.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/bemmanue/LRUCacheService/grpcapi/cachepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type Server struct {
+	cachepb.UnimplementedCacheServer
+
+	cache lrucache.ICacheWithTTL[string, []byte]
+}
+
+func NewServer(cache lrucache.ICacheWithTTL[string, []byte]) *Server {
+	return &Server{cache: cache}
+}
+
+// Register registers the cache service on s.
+func Register(s *grpc.Server, cache lrucache.ICacheWithTTL[string, []byte]) {
+	cachepb.RegisterCacheServer(s, NewServer(cache))
+}
+
+func (s *Server) Get(ctx context.Context, req *cachepb.GetRequest) (*cachepb.GetResponse, error) {
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "key is empty")
+	}
+
+	return s.get(req.Key), nil
+}
+
+func (s *Server) Set(ctx context.Context, req *cachepb.SetRequest) (*cachepb.SetResponse, error) {
+	if err := s.set(req); err != nil {
+		return nil, err
+	}
+
+	return &cachepb.SetResponse{}, nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *cachepb.DeleteRequest) (*cachepb.DeleteResponse, error) {
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "key is empty")
+	}
+
+	_, ok := s.cache.TTL(req.Key)
+	if ok {
+		s.cache.Remove(req.Key)
+	}
+
+	return &cachepb.DeleteResponse{Deleted: ok}, nil
+}
+
+func (s *Server) Clear(ctx context.Context, req *cachepb.ClearRequest) (*cachepb.ClearResponse, error) {
+	s.cache.Clear()
+
+	return &cachepb.ClearResponse{}, nil
+}
+
+func (s *Server) Stats(ctx context.Context, req *cachepb.StatsRequest) (*cachepb.StatsResponse, error) {
+	return &cachepb.StatsResponse{
+		Len: int64(s.cache.Len()),
+		Cap: int64(s.cache.Cap()),
+	}, nil
+}
+
+func (s *Server) BatchGet(stream cachepb.Cache_BatchGetServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if req.Key == "" {
+			return status.Error(codes.InvalidArgument, "key is empty")
+		}
+
+		if err := stream.Send(s.get(req.Key)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) BatchSet(stream cachepb.Cache_BatchSetServer) error {
+	var stored int64
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&cachepb.BatchSetResponse{Stored: stored})
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.set(req); err != nil {
+			return err
+		}
+		stored++
+	}
+}
+
+func (s *Server) get(key string) *cachepb.GetResponse {
+	value, ok := s.cache.Get(key)
+
+	return &cachepb.GetResponse{
+		Key:   key,
+		Value: value,
+		Found: ok,
+	}
+}
+
+func (s *Server) set(req *cachepb.SetRequest) error {
+	if req.Key == "" {
+		return status.Error(codes.InvalidArgument, "key is empty")
+	}
+
+	if req.Ttl == nil {
+		s.cache.Add(req.Key, req.Value)
+		return nil
+	}
+
+	ttl, err := checkTTL(req.Ttl)
+	if err != nil {
+		return err
+	}
+	s.cache.AddWithTTL(req.Key, req.Value, ttl)
+	return nil
+}
+
+func checkTTL(d *durationpb.Duration) (ttl time.Duration, err error) {
+	if err := d.CheckValid(); err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid ttl: %v", err)
+	}
+	if ttl = d.AsDuration(); ttl <= 0 {
+		return 0, status.Error(codes.InvalidArgument, "ttl must be positive")
+	}
+	return ttl, nil
+}
@@ -0,0 +1,227 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/bemmanue/LRUCacheService/grpcapi/cachepb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newTestClient(t *testing.T, cap int) (cachepb.CacheClient, *lrucache.CacheWithTTL2[string, []byte]) {
+	cache := lrucache.NewWithTTL2[string, []byte](cap)
+
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	Register(srv, cache)
+
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return cachepb.NewCacheClient(conn), cache
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func Test_Server_GetSetDelete(t *testing.T) {
+	client, cache := newTestClient(t, 3)
+	ctx := testContext(t)
+
+	get, err := client.Get(ctx, &cachepb.GetRequest{Key: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, false, get.Found)
+
+	_, err = client.Set(ctx, &cachepb.SetRequest{Key: "key", Value: []byte("value")})
+	require.NoError(t, err)
+
+	get, err = client.Get(ctx, &cachepb.GetRequest{Key: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, true, get.Found)
+	assert.Equal(t, "key", get.Key)
+	assert.Equal(t, []byte("value"), get.Value)
+
+	del, err := client.Delete(ctx, &cachepb.DeleteRequest{Key: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, true, del.Deleted)
+
+	del, err = client.Delete(ctx, &cachepb.DeleteRequest{Key: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, false, del.Deleted)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func Test_Server_SetWithTTL(t *testing.T) {
+	client, cache := newTestClient(t, 3)
+	ctx := testContext(t)
+
+	_, err := client.Set(ctx, &cachepb.SetRequest{Key: "key", Value: []byte("value"), Ttl: durationpb.New(time.Hour)})
+	require.NoError(t, err)
+
+	ttl, ok := cache.TTL("key")
+	assert.Equal(t, true, ok)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Second))
+
+	_, err = client.Set(ctx, &cachepb.SetRequest{Key: "key", Value: []byte("value"), Ttl: durationpb.New(20 * time.Millisecond)})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		get, err := client.Get(ctx, &cachepb.GetRequest{Key: "key"})
+		return err == nil && !get.Found
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_Server_InvalidArgument(t *testing.T) {
+	client, _ := newTestClient(t, 3)
+	ctx := testContext(t)
+
+	cases := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "get empty key",
+			call: func() error {
+				_, err := client.Get(ctx, &cachepb.GetRequest{})
+				return err
+			},
+		},
+		{
+			name: "set empty key",
+			call: func() error {
+				_, err := client.Set(ctx, &cachepb.SetRequest{Value: []byte("value")})
+				return err
+			},
+		},
+		{
+			name: "set negative ttl",
+			call: func() error {
+				_, err := client.Set(ctx, &cachepb.SetRequest{Key: "key", Ttl: durationpb.New(-time.Second)})
+				return err
+			},
+		},
+		{
+			name: "set invalid ttl",
+			call: func() error {
+				_, err := client.Set(ctx, &cachepb.SetRequest{Key: "key", Ttl: &durationpb.Duration{Seconds: 1, Nanos: -1}})
+				return err
+			},
+		},
+		{
+			name: "delete empty key",
+			call: func() error {
+				_, err := client.Delete(ctx, &cachepb.DeleteRequest{})
+				return err
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, codes.InvalidArgument, status.Code(c.call()))
+		})
+	}
+}
+
+func Test_Server_ClearAndStats(t *testing.T) {
+	client, cache := newTestClient(t, 5)
+	ctx := testContext(t)
+
+	cache.Add("first", []byte("1"))
+	cache.Add("second", []byte("2"))
+
+	stats, err := client.Stats(ctx, &cachepb.StatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Len)
+	assert.Equal(t, int64(5), stats.Cap)
+
+	_, err = client.Clear(ctx, &cachepb.ClearRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func Test_Server_BatchSet(t *testing.T) {
+	client, cache := newTestClient(t, 5)
+	ctx := testContext(t)
+
+	stream, err := client.BatchSet(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&cachepb.SetRequest{Key: "first", Value: []byte("1")}))
+	require.NoError(t, stream.Send(&cachepb.SetRequest{Key: "second", Value: []byte("2"), Ttl: durationpb.New(time.Hour)}))
+
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Stored)
+
+	value, ok := cache.Get("second")
+	assert.Equal(t, []byte("2"), value)
+	assert.Equal(t, true, ok)
+
+	stream, err = client.BatchSet(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&cachepb.SetRequest{}))
+
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_Server_BatchGet(t *testing.T) {
+	client, cache := newTestClient(t, 5)
+	ctx := testContext(t)
+
+	cache.Add("first", []byte("1"))
+	cache.Add("third", []byte("3"))
+
+	stream, err := client.BatchGet(ctx)
+	require.NoError(t, err)
+
+	for _, key := range []string{"first", "second", "third"} {
+		require.NoError(t, stream.Send(&cachepb.GetRequest{Key: key}))
+	}
+	require.NoError(t, stream.CloseSend())
+
+	var responses []*cachepb.GetResponse
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		responses = append(responses, resp)
+	}
+
+	require.Len(t, responses, 3)
+	assert.Equal(t, "first", responses[0].Key)
+	assert.Equal(t, []byte("1"), responses[0].Value)
+	assert.Equal(t, true, responses[0].Found)
+	assert.Equal(t, "second", responses[1].Key)
+	assert.Equal(t, false, responses[1].Found)
+	assert.Equal(t, "third", responses[2].Key)
+	assert.Equal(t, true, responses[2].Found)
+}
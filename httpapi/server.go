@@ -0,0 +1,160 @@
+// Package httpapi exposes a cache over an HTTP/JSON API.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+)
+
+const keysPrefix = "/keys/"
+
+type Server struct {
+	cache lrucache.ICacheWithTTL[string, []byte]
+	mux   *http.ServeMux
+}
+
+type putRequest struct {
+	Value *string `json:"value"`
+	TTL   string  `json:"ttl,omitempty"`
+}
+
+type keyResponse struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type resizeRequest struct {
+	Cap int `json:"cap"`
+}
+
+type statsResponse struct {
+	Len int `json:"len"`
+	Cap int `json:"cap"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func New(cache lrucache.ICacheWithTTL[string, []byte]) *Server {
+	s := &Server{
+		cache: cache,
+		mux:   http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc(keysPrefix, s.handleKey)
+	s.mux.HandleFunc("/clear", s.handleClear)
+	s.mux.HandleFunc("/stats", s.handleStats)
+	s.mux.HandleFunc("/resize", s.handleResize)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleKey(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, keysPrefix)
+	if key == "" {
+		writeError(w, http.StatusNotFound, errors.New("key is empty"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		value, ok := s.cache.Get(key)
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("key not found"))
+			return
+		}
+		writeJSON(w, http.StatusOK, keyResponse{Key: key, Value: string(value)})
+	case http.MethodPut:
+		var req putRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid request body"))
+			return
+		}
+		if req.Value == nil {
+			writeError(w, http.StatusBadRequest, errors.New("value is required"))
+			return
+		}
+
+		if req.TTL == "" {
+			s.cache.Add(key, []byte(*req.Value))
+		} else {
+			ttl, err := time.ParseDuration(req.TTL)
+			if err != nil || ttl <= 0 {
+				writeError(w, http.StatusBadRequest, errors.New("ttl must be a positive duration"))
+				return
+			}
+			s.cache.AddWithTTL(key, []byte(*req.Value), ttl)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		s.cache.Remove(key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+func (s *Server) handleClear(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	s.cache.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, statsResponse{
+		Len: s.cache.Len(),
+		Cap: s.cache.Cap(),
+	})
+}
+
+func (s *Server) handleResize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	var req resizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid request body"))
+		return
+	}
+	if err := s.cache.Resize(req.Cap); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
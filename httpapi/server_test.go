@@ -0,0 +1,158 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, cap int) (*httptest.Server, *lrucache.CacheWithTTL2[string, []byte]) {
+	cache := lrucache.NewWithTTL2[string, []byte](cap)
+	srv := httptest.NewServer(New(cache))
+	t.Cleanup(srv.Close)
+	return srv, cache
+}
+
+func do(t *testing.T, method, url, body string) (int, string) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(data)
+}
+
+func Test_Server_Keys(t *testing.T) {
+	srv, cache := newTestServer(t, 3)
+
+	status, _ := do(t, http.MethodPut, srv.URL+"/keys/first", `{"value":"one"}`)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	status, body := do(t, http.MethodGet, srv.URL+"/keys/first", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"key":"first","value":"one"}`, body)
+
+	status, _ = do(t, http.MethodPut, srv.URL+"/keys/a%2Fb", `{"value":"slash"}`)
+	assert.Equal(t, http.StatusNoContent, status)
+	value, ok := cache.Get("a/b")
+	assert.Equal(t, "slash", string(value))
+	assert.Equal(t, true, ok)
+
+	status, _ = do(t, http.MethodDelete, srv.URL+"/keys/first", "")
+	assert.Equal(t, http.StatusNoContent, status)
+
+	status, body = do(t, http.MethodGet, srv.URL+"/keys/first", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.JSONEq(t, `{"error":"key not found"}`, body)
+}
+
+func Test_Server_PutWithTTL(t *testing.T) {
+	srv, _ := newTestServer(t, 3)
+
+	status, _ := do(t, http.MethodPut, srv.URL+"/keys/key", `{"value":"value","ttl":"20ms"}`)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	status, _ = do(t, http.MethodGet, srv.URL+"/keys/key", "")
+	assert.Equal(t, http.StatusOK, status)
+
+	require.Eventually(t, func() bool {
+		status, _ := do(t, http.MethodGet, srv.URL+"/keys/key", "")
+		return status == http.StatusNotFound
+	}, time.Second, time.Millisecond*10)
+}
+
+func Test_Server_PutInvalid(t *testing.T) {
+	srv, _ := newTestServer(t, 3)
+
+	cases := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed json", body: `{"value":`},
+		{name: "missing value", body: `{"ttl":"1s"}`},
+		{name: "invalid ttl", body: `{"value":"v","ttl":"soon"}`},
+		{name: "negative ttl", body: `{"value":"v","ttl":"-1s"}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, _ := do(t, http.MethodPut, srv.URL+"/keys/key", c.body)
+			assert.Equal(t, http.StatusBadRequest, status)
+		})
+	}
+}
+
+func Test_Server_ClearAndStats(t *testing.T) {
+	srv, cache := newTestServer(t, 5)
+
+	cache.Add("first", []byte("1"))
+	cache.Add("second", []byte("2"))
+
+	status, body := do(t, http.MethodGet, srv.URL+"/stats", "")
+	assert.Equal(t, http.StatusOK, status)
+
+	var stats statsResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &stats))
+	assert.Equal(t, statsResponse{Len: 2, Cap: 5}, stats)
+
+	status, _ = do(t, http.MethodPost, srv.URL+"/clear", "")
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func Test_Server_Resize(t *testing.T) {
+	srv, cache := newTestServer(t, 5)
+
+	cache.Add("first", []byte("1"))
+	cache.Add("second", []byte("2"))
+	cache.Add("third", []byte("3"))
+
+	status, _ := do(t, http.MethodPost, srv.URL+"/resize", `{"cap": 2}`)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, 2, cache.Cap())
+	assert.Equal(t, 2, cache.Len())
+	_, ok := cache.Get("first")
+	assert.False(t, ok)
+
+	status, body := do(t, http.MethodPost, srv.URL+"/resize", `{"cap": 0}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "capacity must be positive")
+
+	status, _ = do(t, http.MethodPost, srv.URL+"/resize", `{`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, 2, cache.Cap())
+}
+
+func Test_Server_MethodNotAllowed(t *testing.T) {
+	srv, _ := newTestServer(t, 3)
+
+	cases := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodPost, path: "/keys/key"},
+		{method: http.MethodGet, path: "/clear"},
+		{method: http.MethodDelete, path: "/stats"},
+		{method: http.MethodGet, path: "/resize"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.method+" "+c.path, func(t *testing.T) {
+			status, _ := do(t, c.method, srv.URL+c.path, "")
+			assert.Equal(t, http.StatusMethodNotAllowed, status)
+		})
+	}
+}
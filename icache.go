@@ -0,0 +1,38 @@
+package lrucache
+
+import "time"
+
+// NoExpiration is reported by TTL for elements that were added without TTL.
+// Passed as a TTL, it stores the element without expiration regardless of
+// the default TTL.
+const NoExpiration time.Duration = -1
+
+// ICache is the set of operations shared by every cache in the package.
+type ICache[K comparable, V any] interface {
+	Cap() int
+	// Resize changes the capacity, evicting elements if it shrinks.
+	Resize(newCap int) error
+	Len() int
+	Clear()
+	Add(key K, value V)
+	Get(key K) (value V, ok bool)
+	Remove(key K)
+	// Close stops the background work of the cache, if there is any.
+	Close()
+}
+
+// ICacheWithTTL is implemented by caches that can expire elements.
+type ICacheWithTTL[K comparable, V any] interface {
+	ICache[K, V]
+	AddWithTTL(key K, value V, ttl time.Duration)
+	// Replace changes the value of an existing key without changing its TTL.
+	Replace(key K, value V) bool
+	TTL(key K) (ttl time.Duration, ok bool)
+}
+
+var (
+	_ ICache[string, any]        = (*Cache[string, any])(nil)
+	_ ICacheWithTTL[string, any] = (*CacheWithTTL[string, any])(nil)
+	_ ICacheWithTTL[string, any] = (*CacheWithTTL2[string, any])(nil)
+	_ ICacheWithTTL[string, any] = (*ShardedCache[string, any])(nil)
+)
@@ -0,0 +1,306 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type cacheFactory struct {
+	name string
+	new  func(t *testing.T, cap int, opts ...Option[string, any]) ICache[string, any]
+}
+
+type cacheWithTTLFactory struct {
+	name string
+	new  func(t *testing.T, cap int, opts ...Option[string, any]) ICacheWithTTL[string, any]
+}
+
+func cacheFactories() []cacheFactory {
+	factories := []cacheFactory{
+		{
+			name: "Cache",
+			new: func(t *testing.T, cap int, opts ...Option[string, any]) ICache[string, any] {
+				return New[string, any](cap, opts...)
+			},
+		},
+	}
+
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		factories = append(factories, cacheFactory{
+			name: f.name,
+			new: func(t *testing.T, cap int, opts ...Option[string, any]) ICache[string, any] {
+				return f.new(t, cap, opts...)
+			},
+		})
+	}
+
+	return factories
+}
+
+func cacheWithTTLFactories() []cacheWithTTLFactory {
+	return []cacheWithTTLFactory{
+		{
+			name: "CacheWithTTL",
+			new: func(t *testing.T, cap int, opts ...Option[string, any]) ICacheWithTTL[string, any] {
+				cache, cancel := NewWithTTL[string, any](cap, time.Millisecond*10, opts...)
+				t.Cleanup(cancel)
+
+				var o options[string, any]
+				for _, opt := range opts {
+					opt(&o)
+				}
+				if clock, ok := o.clock.(*FakeClock); ok {
+					// let the background goroutine wait for the clock, see advance
+					require.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Microsecond*100)
+				}
+				return cache
+			},
+		},
+		{
+			name: "CacheWithTTL2",
+			new: func(t *testing.T, cap int, opts ...Option[string, any]) ICacheWithTTL[string, any] {
+				return NewWithTTL2[string, any](cap, opts...)
+			},
+		},
+		{
+			name: "CacheWithTTL2/TimingWheel",
+			new: func(t *testing.T, cap int, opts ...Option[string, any]) ICacheWithTTL[string, any] {
+				return NewWithTTL2[string, any](cap, append([]Option[string, any]{WithTimingWheel[string, any]()}, opts...)...)
+			},
+		},
+		{
+			// a single shard keeps the eviction order of the other caches
+			name: "ShardedCache",
+			new: func(t *testing.T, cap int, opts ...Option[string, any]) ICacheWithTTL[string, any] {
+				return NewSharded[string, any](cap, append([]Option[string, any]{WithShards[string, any](1)}, opts...)...)
+			},
+		},
+	}
+}
+
+func Test_ICache(t *testing.T) {
+	for _, f := range cacheFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			t.Run("cap", func(t *testing.T) {
+				cache := f.new(t, 5)
+
+				assert.Equal(t, 5, cache.Cap())
+				assert.Equal(t, 0, cache.Len())
+			})
+
+			t.Run("add and get", func(t *testing.T) {
+				cache := f.new(t, 3)
+
+				cache.Add("first", 1)
+				cache.Add("second", "two")
+
+				value, ok := cache.Get("first")
+				assert.Equal(t, 1, value)
+				assert.Equal(t, true, ok)
+
+				value, ok = cache.Get("second")
+				assert.Equal(t, "two", value)
+				assert.Equal(t, true, ok)
+
+				value, ok = cache.Get("third")
+				assert.Nil(t, value)
+				assert.Equal(t, false, ok)
+				assert.Equal(t, 2, cache.Len())
+			})
+
+			t.Run("update", func(t *testing.T) {
+				cache := f.new(t, 3)
+
+				cache.Add("key", "value")
+				cache.Add("key", "another value")
+
+				value, ok := cache.Get("key")
+				assert.Equal(t, "another value", value)
+				assert.Equal(t, true, ok)
+				assert.Equal(t, 1, cache.Len())
+			})
+
+			t.Run("evicts least recently used", func(t *testing.T) {
+				cache := f.new(t, 3)
+
+				cache.Add("first", 1)
+				cache.Add("second", 2)
+				cache.Add("third", 3)
+				cache.Get("first")
+				cache.Add("forth", 4)
+
+				_, ok := cache.Get("second")
+				assert.Equal(t, false, ok)
+
+				for _, key := range []string{"first", "third", "forth"} {
+					_, ok := cache.Get(key)
+					assert.Equal(t, true, ok, key)
+				}
+				assert.Equal(t, 3, cache.Len())
+			})
+
+			t.Run("remove", func(t *testing.T) {
+				cache := f.new(t, 3)
+
+				cache.Add("first", 1)
+				cache.Add("second", 2)
+				cache.Remove("first")
+				cache.Remove("random key")
+
+				_, ok := cache.Get("first")
+				assert.Equal(t, false, ok)
+				assert.Equal(t, 1, cache.Len())
+			})
+
+			t.Run("clear", func(t *testing.T) {
+				cache := f.new(t, 3)
+
+				for i := 0; i < 3; i++ {
+					cache.Add(strconv.Itoa(i), i)
+				}
+				cache.Clear()
+
+				assert.Equal(t, 0, cache.Len())
+				assert.Equal(t, 3, cache.Cap())
+
+				cache.Add("key", "value")
+				assert.Equal(t, 1, cache.Len())
+			})
+		})
+	}
+}
+
+func Test_ICacheWithTTL(t *testing.T) {
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			t.Run("expires", func(t *testing.T) {
+				clock := NewFakeClock(time.Now())
+				cache := f.new(t, 3, WithClock[string, any](clock))
+
+				cache.AddWithTTL("key", "value", time.Millisecond*20)
+
+				advance(t, clock, time.Millisecond*15)
+				value, ok := cache.Get("key")
+				assert.Equal(t, "value", value)
+				assert.Equal(t, true, ok)
+
+				advance(t, clock, time.Millisecond*15)
+				_, ok = cache.Get("key")
+				assert.Equal(t, false, ok)
+				assert.Equal(t, 0, cache.Len())
+			})
+
+			t.Run("replace keeps ttl", func(t *testing.T) {
+				clock := NewFakeClock(time.Now())
+				cache := f.new(t, 3, WithClock[string, any](clock), WithDefaultTTL[string, any](time.Hour))
+
+				cache.AddWithTTL("key", 1, time.Minute)
+				advance(t, clock, time.Second)
+				assert.True(t, cache.Replace("key", 2))
+				assert.False(t, cache.Replace("missing", 3))
+
+				value, _ := cache.Get("key")
+				assert.Equal(t, 2, value)
+				ttl, _ := cache.TTL("key")
+				assert.Equal(t, time.Minute-time.Second, ttl)
+				_, ok := cache.Get("missing")
+				assert.False(t, ok)
+			})
+
+			t.Run("expires out of order", func(t *testing.T) {
+				clock := NewFakeClock(time.Now())
+				cache := f.new(t, 4, WithClock[string, any](clock))
+
+				cache.AddWithTTL("late", 1, time.Hour)
+				cache.AddWithTTL("early", 2, time.Millisecond*20)
+				cache.Add("forever", 3)
+
+				advance(t, clock, time.Millisecond*30)
+				_, ok := cache.Get("early")
+				assert.Equal(t, false, ok)
+
+				_, ok = cache.Get("late")
+				assert.Equal(t, true, ok)
+				_, ok = cache.Get("forever")
+				assert.Equal(t, true, ok)
+				assert.Equal(t, 2, cache.Len())
+			})
+
+			t.Run("extends ttl", func(t *testing.T) {
+				clock := NewFakeClock(time.Now())
+				cache := f.new(t, 3, WithClock[string, any](clock))
+
+				cache.AddWithTTL("key", "value", time.Millisecond*20)
+				cache.AddWithTTL("key", "value", time.Hour)
+
+				advance(t, clock, time.Millisecond*50)
+
+				_, ok := cache.Get("key")
+				assert.Equal(t, true, ok)
+			})
+
+			t.Run("updates value", func(t *testing.T) {
+				cache := f.new(t, 3)
+
+				cache.Add("key", "value")
+				cache.AddWithTTL("key", "another value", time.Hour)
+
+				value, ok := cache.Get("key")
+				assert.Equal(t, "another value", value)
+				assert.Equal(t, true, ok)
+				assert.Equal(t, 1, cache.Len())
+			})
+
+			t.Run("add drops ttl", func(t *testing.T) {
+				clock := NewFakeClock(time.Now())
+				cache := f.new(t, 3, WithClock[string, any](clock))
+
+				cache.AddWithTTL("key", "value", time.Millisecond*20)
+				cache.Add("key", "another value")
+
+				advance(t, clock, time.Millisecond*50)
+
+				value, ok := cache.Get("key")
+				assert.Equal(t, "another value", value)
+				assert.Equal(t, true, ok)
+
+				ttl, ok := cache.TTL("key")
+				assert.Equal(t, NoExpiration, ttl)
+				assert.Equal(t, true, ok)
+			})
+
+			t.Run("ttl", func(t *testing.T) {
+				clock := NewFakeClock(time.Now())
+				cache := f.new(t, 3, WithClock[string, any](clock))
+
+				cache.AddWithTTL("key", "value", time.Hour)
+				clock.Advance(time.Minute)
+
+				ttl, ok := cache.TTL("key")
+				assert.Equal(t, true, ok)
+				assert.Equal(t, time.Hour-time.Minute, ttl)
+
+				_, ok = cache.TTL("random key")
+				assert.Equal(t, false, ok)
+			})
+
+			t.Run("remove", func(t *testing.T) {
+				cache := f.new(t, 3)
+
+				cache.AddWithTTL("first", 1, time.Hour)
+				cache.AddWithTTL("second", 2, time.Hour)
+				cache.Remove("first")
+
+				_, ok := cache.Get("first")
+				assert.Equal(t, false, ok)
+				assert.Equal(t, 1, cache.Len())
+			})
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Package tcpserver accepts connections for the line-based protocol servers
+// and tracks them so that they can be closed on shutdown.
+package tcpserver
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+var ErrServerClosed = errors.New("server closed")
+
+type Server struct {
+	// Handler serves a single connection. The connection is closed once
+	// Handler returns.
+	Handler func(conn net.Conn)
+
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// Serve accepts connections on l until Close is called, in which case it
+// returns ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.track(l) {
+		return ErrServerClosed
+	}
+	defer s.untrack(l)
+
+	var delay time.Duration
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				if delay == 0 {
+					delay = 5 * time.Millisecond
+				} else if delay *= 2; delay > time.Second {
+					delay = time.Second
+				}
+				time.Sleep(delay)
+				continue
+			}
+			return err
+		}
+		delay = 0
+
+		if !s.trackConn(conn) {
+			conn.Close()
+			return ErrServerClosed
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrackConn(conn)
+			defer conn.Close()
+
+			s.Handler(conn)
+		}()
+	}
+}
+
+// Close stops all listeners, closes open connections and waits for their
+// handlers to return.
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+
+	var err error
+	for l := range s.listeners {
+		if closeErr := l.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) track(l net.Listener) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return false
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+	}
+	s.listeners[l] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(l net.Listener) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.listeners, l)
+}
+
+func (s *Server) trackConn(conn net.Conn) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return false
+	}
+	if s.conns == nil {
+		s.conns = make(map[net.Conn]struct{})
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrackConn(conn net.Conn) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.conns, conn)
+}
+
+func (s *Server) isClosed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.closed
+}
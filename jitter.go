@@ -0,0 +1,99 @@
+package lrucache
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// ttlJitter randomly changes TTLs so that values added at the same time with
+// the same TTL don't expire at once. It is only used with the write lock
+// held, so a single rand.Rand is enough.
+type ttlJitter struct {
+	fraction float64 // relative jitter, 0 if absolute bounds are used
+	min, max time.Duration
+	rand     *rand.Rand
+}
+
+// newTTLJitter returns nil if jitter is disabled or invalid, see jitterError.
+func newTTLJitter[K comparable, V any](o options[K, V]) *ttlJitter {
+	if o.jitterFraction == 0 && o.jitterMin == 0 && o.jitterMax == 0 || o.jitterError() != nil {
+		return nil
+	}
+
+	seed := o.jitterSeed
+	if !o.jitterSeeded {
+		seed = rand.Int63()
+	}
+
+	return &ttlJitter{
+		fraction: o.jitterFraction,
+		min:      o.jitterMin,
+		max:      o.jitterMax,
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// jitterError returns the error NewCache reports for invalid jitter options.
+func (o *options[K, V]) jitterError() error {
+	switch {
+	case o.jitterFraction < 0 || o.jitterFraction >= 1:
+		return fmt.Errorf("%w: TTL jitter must be at least 0 and less than 1, got %v", ErrInvalidOption, o.jitterFraction)
+	case o.jitterMin > o.jitterMax:
+		return fmt.Errorf("%w: TTL jitter bounds %v and %v are reversed", ErrInvalidOption, o.jitterMin, o.jitterMax)
+	case o.jitterFraction != 0 && (o.jitterMin != 0 || o.jitterMax != 0):
+		return fmt.Errorf("%w: TTL jitter is set both as a fraction and as bounds", ErrInvalidOption)
+	}
+	return nil
+}
+
+// apply returns ttl changed by a random amount within the bounds, but not
+// below 1ns so that jitter never expires a value right away.
+func (j *ttlJitter) apply(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return ttl
+	}
+
+	var jittered time.Duration
+	if j.fraction > 0 {
+		jittered = ttl + time.Duration((j.rand.Float64()*2-1)*j.fraction*float64(ttl))
+	} else {
+		jittered = ttl + j.min + time.Duration(j.rand.Int63n(int64(j.max-j.min)+1))
+	}
+	if jittered <= 0 {
+		return time.Nanosecond
+	}
+	return jittered
+}
+
+// WithTTLJitter changes every TTL passed to AddWithTTL or applied by default
+// by a random amount of up to fraction of it in either direction, for example
+// 0.1 spreads a TTL of 10 minutes between 9 and 11 minutes. The fraction must
+// be less than 1; NewCache rejects other values and the other constructors
+// disable jitter then.
+func WithTTLJitter[K comparable, V any](fraction float64) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.jitterFraction = fraction
+	}
+}
+
+// WithTTLJitterBounds adds a random duration between min and max to every TTL
+// passed to AddWithTTL or applied by default. min can be negative, but
+// jittered TTLs stay positive. Reversed bounds are handled as in
+// WithTTLJitter.
+func WithTTLJitterBounds[K comparable, V any](min, max time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.jitterMin = min
+		o.jitterMax = max
+	}
+}
+
+// WithJitterSeed seeds the random numbers of the TTL jitter, so that tests
+// get the same TTLs on every run. Shards of a ShardedCache get consecutive
+// seeds.
+func WithJitterSeed[K comparable, V any](seed int64) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.jitterSeed = seed
+		o.jitterSeeded = true
+	}
+}
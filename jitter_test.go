@@ -0,0 +1,151 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// jitteredTTLs adds n keys with ttl and returns the TTLs they got.
+func jitteredTTLs(t *testing.T, cache ICacheWithTTL[string, int], n int, ttl time.Duration) []time.Duration {
+	ttls := make([]time.Duration, n)
+	for i := range ttls {
+		key := strconv.Itoa(i)
+		cache.AddWithTTL(key, i, ttl)
+
+		var ok bool
+		ttls[i], ok = cache.TTL(key)
+		require.True(t, ok)
+	}
+	return ttls
+}
+
+func Test_TTLJitter(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	newCache := func() *CacheWithTTL2[string, int] {
+		return NewWithTTL2[string, int](1000,
+			WithTTLJitter[string, int](0.1),
+			WithJitterSeed[string, int](1),
+			WithClock[string, int](clock),
+		)
+	}
+
+	ttls := jitteredTTLs(t, newCache(), 1000, time.Minute*10)
+
+	distinct := make(map[time.Duration]struct{})
+	for _, ttl := range ttls {
+		assert.GreaterOrEqual(t, ttl, time.Minute*9)
+		assert.LessOrEqual(t, ttl, time.Minute*11)
+		distinct[ttl] = struct{}{}
+	}
+	assert.Greater(t, len(distinct), 900)
+
+	// the same seed gives the same TTLs
+	assert.Equal(t, ttls, jitteredTTLs(t, newCache(), 1000, time.Minute*10))
+}
+
+func Test_TTLJitterBounds(t *testing.T) {
+	cache := NewWithTTL2[string, int](100,
+		WithTTLJitterBounds[string, int](-time.Second, time.Second*5),
+		WithClock[string, int](NewFakeClock(time.Now())),
+	)
+
+	for _, ttl := range jitteredTTLs(t, cache, 100, time.Minute) {
+		assert.GreaterOrEqual(t, ttl, time.Minute-time.Second)
+		assert.LessOrEqual(t, ttl, time.Minute+time.Second*5)
+	}
+
+	// NoExpiration is not jittered
+	cache.AddWithTTL("never", 1, NoExpiration)
+	ttl, _ := cache.TTL("never")
+	assert.Equal(t, NoExpiration, ttl)
+}
+
+func Test_TTLJitter_Invalid(t *testing.T) {
+	tests := map[string]Option[string, int]{
+		"reversed bounds":   WithTTLJitterBounds[string, int](time.Second*5, time.Second),
+		"fraction over 1":   WithTTLJitter[string, int](1.5),
+		"negative fraction": WithTTLJitter[string, int](-0.5),
+	}
+
+	for name, opt := range tests {
+		opt := opt
+		t.Run(name, func(t *testing.T) {
+			// constructors that can't report errors disable invalid jitter
+			cache := NewWithTTL2[string, int](100, opt, WithClock[string, int](NewFakeClock(time.Now())))
+			for _, ttl := range jitteredTTLs(t, cache, 100, time.Minute) {
+				assert.Equal(t, time.Minute, ttl)
+			}
+		})
+	}
+}
+
+func Test_TTLJitter_Positive(t *testing.T) {
+	cache := NewWithTTL2[string, int](100,
+		WithTTLJitterBounds[string, int](-time.Minute, 0),
+		WithClock[string, int](NewFakeClock(time.Now())),
+	)
+
+	for _, ttl := range jitteredTTLs(t, cache, 100, time.Second) {
+		assert.Greater(t, ttl, time.Duration(0))
+	}
+}
+
+func Test_TTLJitter_Expiration(t *testing.T) {
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 100,
+				WithDefaultTTL[string, any](time.Minute),
+				WithTTLJitter[string, any](0.5),
+				WithJitterSeed[string, any](1),
+				WithClock[string, any](clock),
+			)
+
+			// values added at once with the default TTL expire over time
+			for i := 0; i < 100; i++ {
+				cache.Add(strconv.Itoa(i), i)
+			}
+
+			alive := func() int {
+				n := 0
+				for i := 0; i < 100; i++ {
+					if _, ok := cache.Get(strconv.Itoa(i)); ok {
+						n++
+					}
+				}
+				return n
+			}
+
+			advance(t, clock, time.Second*30)
+			assert.Equal(t, 100, alive())
+			advance(t, clock, time.Second*30)
+			assert.InDelta(t, 50, alive(), 20)
+			advance(t, clock, time.Second*31)
+			assert.Equal(t, 0, alive())
+		})
+	}
+}
+
+func Test_TTLJitter_Sharded(t *testing.T) {
+	newCache := func() *ShardedCache[string, int] {
+		return NewSharded[string, int](100,
+			WithShards[string, int](2),
+			WithHash[string, int](func(key string) uint64 { return 0 }),
+			WithTTLJitter[string, int](0.1),
+			WithJitterSeed[string, int](1),
+			WithClock[string, int](NewFakeClock(time.Now())),
+		)
+	}
+
+	cache := newCache()
+	ttls := jitteredTTLs(t, cache, 50, time.Minute)
+	assert.Equal(t, ttls, jitteredTTLs(t, newCache(), 50, time.Minute))
+
+	// shards don't repeat each other
+	cache.hash = func(key string) uint64 { return 1 }
+	assert.NotEqual(t, ttls, jitteredTTLs(t, cache, 50, time.Minute))
+}
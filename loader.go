@@ -0,0 +1,167 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Loader computes the value of a missing key. A positive ttl makes the value
+// expire in caches that support TTL, NoExpiration stores it without
+// expiration and any other ttl applies the default TTL, see WithDefaultTTL.
+// Errors are returned to the caller and are only cached by caches created
+// with WithNegativeTTL.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (value V, ttl time.Duration, err error)
+
+var errLoaderPanicked = errors.New("lrucache: loader panicked")
+
+// loadCall is a loader call in progress, shared by callers of the same key.
+type loadCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// GetOrLoad returns the cached value or, on a miss, calls loader and stores
+// its result. Concurrent misses of the same key wait for a single loader call.
+// Cache has no expiration, so the ttl returned by the loader is ignored.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (V, error) {
+	return c.getOrLoad(ctx, key, loader, c.lookup, func(key K, value V, _ time.Duration) {
+		c.Add(key, value)
+	}, nil)
+}
+
+// getOrLoad implements GetOrLoad with the lookup and store functions of the
+// concrete cache type. storeNegative is nil if loader errors are not cached.
+func (c *Cache[K, V]) getOrLoad(
+	ctx context.Context,
+	key K,
+	loader Loader[K, V],
+	lookup func(key K) (V, bool, error),
+	store func(key K, value V, ttl time.Duration),
+	storeNegative func(key K, err error),
+) (V, error) {
+	if value, ok, err := lookup(key); ok {
+		return value, err
+	}
+
+	for {
+		c.loadMutex.Lock()
+		if call, ok := c.loads[key]; ok {
+			c.loadMutex.Unlock()
+
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				var zero V
+				return zero, ctx.Err()
+			}
+
+			// the caller that started the load gave up, try again with our context
+			if isContextError(call.err) && ctx.Err() == nil {
+				continue
+			}
+			return call.value, call.err
+		}
+
+		call := &loadCall[V]{
+			done: make(chan struct{}),
+			err:  errLoaderPanicked,
+		}
+		if c.loads == nil {
+			c.loads = make(map[K]*loadCall[V])
+		}
+		c.loads[key] = call
+		c.loadMutex.Unlock()
+
+		c.runLoad(ctx, key, loader, call, lookup, store, storeNegative)
+		return call.value, call.err
+	}
+}
+
+func (c *Cache[K, V]) runLoad(
+	ctx context.Context,
+	key K,
+	loader Loader[K, V],
+	call *loadCall[V],
+	lookup func(key K) (V, bool, error),
+	store func(key K, value V, ttl time.Duration),
+	storeNegative func(key K, err error),
+) {
+	defer func() {
+		c.loadMutex.Lock()
+		delete(c.loads, key)
+		c.loadMutex.Unlock()
+
+		close(call.done)
+	}()
+
+	// another load could have stored the value after our miss
+	if value, ok, err := lookup(key); ok {
+		call.value, call.err = value, err
+		return
+	}
+
+	value, ttl, err := loader(ctx, key)
+	if err != nil {
+		if storeNegative != nil && !isContextError(err) {
+			storeNegative(key, err)
+		}
+
+		var zero V
+		call.value, call.err = zero, err
+		return
+	}
+
+	store(key, value, ttl)
+	call.value, call.err = value, nil
+}
+
+// GetOrLoad returns the cached value or, on a miss, calls loader and stores
+// its result with the TTL returned by the loader. Concurrent misses of the
+// same key wait for a single loader call. Negative entries make it return
+// their error without calling the loader.
+func (c *CacheWithTTL[K, V]) GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (V, error) {
+	return c.getOrLoad(ctx, key, loader, c.Lookup, c.addLoaded, c.storeNegative())
+}
+
+// storeNegative returns the function that caches loader errors, or nil if
+// negative caching is disabled.
+func (c *CacheWithTTL[K, V]) storeNegative() func(key K, err error) {
+	if c.negativeTTL <= 0 {
+		return nil
+	}
+	return func(key K, err error) {
+		c.AddNegative(key, err, c.negativeTTL)
+	}
+}
+
+// store adds the value with TTL if ttl is positive and without it otherwise.
+func (c *CacheWithTTL2[K, V]) store(key K, value V, ttl time.Duration) {
+	c.UpdateExpirations()
+
+	c.addLoaded(key, value, ttl)
+}
+
+// GetOrLoad returns the cached value or, on a miss, calls loader and stores
+// its result with the TTL returned by the loader. Concurrent misses of the
+// same key wait for a single loader call. Negative entries make it return
+// their error without calling the loader.
+func (c *CacheWithTTL2[K, V]) GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (V, error) {
+	return c.getOrLoad(ctx, key, loader, c.Lookup, c.store, c.storeNegative())
+}
+
+// storeNegative returns the function that caches loader errors, or nil if
+// negative caching is disabled.
+func (c *CacheWithTTL2[K, V]) storeNegative() func(key K, err error) {
+	if c.negativeTTL <= 0 {
+		return nil
+	}
+	return func(key K, err error) {
+		c.AddNegative(key, err, c.negativeTTL)
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
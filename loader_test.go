@@ -0,0 +1,160 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type loadingCache interface {
+	GetOrLoad(ctx context.Context, key string, loader Loader[string, any]) (any, error)
+}
+
+func Test_GetOrLoad(t *testing.T) {
+	for _, f := range cacheFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			cache := f.new(t, 10)
+			loading := cache.(loadingCache)
+			ctx := context.Background()
+
+			var calls atomic.Int32
+			release := make(chan struct{})
+			loader := func(ctx context.Context, key string) (any, time.Duration, error) {
+				calls.Add(1)
+				<-release
+				return key + " value", 0, nil
+			}
+
+			var wg sync.WaitGroup
+			results := make([]any, 50)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					value, err := loading.GetOrLoad(ctx, "key", loader)
+					assert.NoError(t, err)
+					results[i] = value
+				}(i)
+			}
+
+			require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+			time.Sleep(time.Millisecond * 10)
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, int32(1), calls.Load())
+			for _, value := range results {
+				assert.Equal(t, "key value", value)
+			}
+
+			value, ok := cache.Get("key")
+			assert.True(t, ok)
+			assert.Equal(t, "key value", value)
+
+			value, err := loading.GetOrLoad(ctx, "key", loader)
+			assert.NoError(t, err)
+			assert.Equal(t, "key value", value)
+			assert.Equal(t, int32(1), calls.Load())
+		})
+
+		t.Run(f.name+"/error", func(t *testing.T) {
+			cache := f.new(t, 10)
+			loading := cache.(loadingCache)
+			loadErr := errors.New("load failed")
+
+			var calls int
+			loader := func(ctx context.Context, key string) (any, time.Duration, error) {
+				calls++
+				return nil, 0, loadErr
+			}
+
+			_, err := loading.GetOrLoad(context.Background(), "key", loader)
+			assert.ErrorIs(t, err, loadErr)
+			_, err = loading.GetOrLoad(context.Background(), "key", loader)
+			assert.ErrorIs(t, err, loadErr)
+
+			assert.Equal(t, 2, calls)
+			assert.Equal(t, 0, cache.Len())
+		})
+
+		t.Run(f.name+"/context", func(t *testing.T) {
+			cache := f.new(t, 10)
+			loading := cache.(loadingCache)
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			firstCtx, cancelFirst := context.WithCancel(context.Background())
+			go func() {
+				_, _ = loading.GetOrLoad(firstCtx, "key", func(ctx context.Context, key string) (any, time.Duration, error) {
+					close(started)
+					select {
+					case <-ctx.Done():
+						return nil, 0, ctx.Err()
+					case <-release:
+						return "first", 0, nil
+					}
+				})
+			}()
+			<-started
+
+			// a waiter gives up with its own context
+			waiterCtx, cancelWaiter := context.WithTimeout(context.Background(), time.Millisecond*10)
+			defer cancelWaiter()
+			_, err := loading.GetOrLoad(waiterCtx, "key", nil)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+			// a waiter retries when the first caller gives up
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				value, err := loading.GetOrLoad(context.Background(), "key", func(ctx context.Context, key string) (any, time.Duration, error) {
+					return "second", 0, nil
+				})
+				assert.NoError(t, err)
+				assert.Equal(t, "second", value)
+			}()
+			time.Sleep(time.Millisecond * 10)
+			cancelFirst()
+			<-done
+			close(release)
+		})
+	}
+
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name+"/ttl", func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 10, WithClock[string, any](clock))
+			loading := cache.(loadingCache)
+
+			value, err := loading.GetOrLoad(context.Background(), "key", func(ctx context.Context, key string) (any, time.Duration, error) {
+				return 1, time.Millisecond * 30, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 1, value)
+
+			ttl, ok := cache.TTL("key")
+			assert.True(t, ok)
+			assert.Equal(t, time.Millisecond*30, ttl)
+
+			advance(t, clock, time.Millisecond*40)
+			_, ok = cache.Get("key")
+			assert.False(t, ok)
+
+			_, err = loading.GetOrLoad(context.Background(), "other", func(ctx context.Context, key string) (any, time.Duration, error) {
+				return 2, 0, nil
+			})
+			require.NoError(t, err)
+
+			ttl, ok = cache.TTL("other")
+			assert.True(t, ok)
+			assert.Equal(t, NoExpiration, ttl)
+		})
+	}
+}
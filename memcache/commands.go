@@ -0,0 +1,399 @@
+package memcache
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+)
+
+// maxRelativeExptime is the largest exptime that is treated as a number of
+// seconds from now, larger values are unix timestamps.
+const maxRelativeExptime = 60 * 60 * 24 * 30
+
+const version = "1.6.0-lrucache"
+
+type storeMode int
+
+const (
+	modeSet storeMode = iota
+	modeAdd
+	modeReplace
+	modeCAS
+)
+
+func (s *Server) exec(c *conn, line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		c.reply("ERROR")
+		return
+	}
+
+	switch name, args := fields[0], fields[1:]; name {
+	case "get":
+		s.get(c, args, false)
+	case "gets":
+		s.get(c, args, true)
+	case "set":
+		s.store(c, args, modeSet)
+	case "add":
+		s.store(c, args, modeAdd)
+	case "replace":
+		s.store(c, args, modeReplace)
+	case "cas":
+		s.store(c, args, modeCAS)
+	case "delete":
+		s.delete(c, args)
+	case "touch":
+		s.touch(c, args)
+	case "flush_all":
+		s.flushAll(c, args)
+	case "stats":
+		s.writeStats(c, args)
+	case "version":
+		c.reply("VERSION " + version)
+	case "verbosity":
+		if len(args) == 0 {
+			c.reply("ERROR")
+			return
+		}
+		if !noreply(args) {
+			c.reply("OK")
+		}
+	case "quit":
+		c.quit = true
+	default:
+		c.reply("ERROR")
+	}
+}
+
+// get handles "get <key>*" and "gets <key>*".
+func (s *Server) get(c *conn, keys []string, withCAS bool) {
+	if len(keys) == 0 {
+		c.reply("ERROR")
+		return
+	}
+	for _, key := range keys {
+		if !validKey(key) {
+			c.reply("CLIENT_ERROR bad command line format")
+			return
+		}
+	}
+
+	for _, key := range keys {
+		s.stats.cmdGet.Add(1)
+
+		item, ok := s.cache.Get(key)
+		if !ok {
+			s.stats.getMisses.Add(1)
+			continue
+		}
+		s.stats.getHits.Add(1)
+
+		if withCAS {
+			fmt.Fprintf(c.w, "VALUE %s %d %d %d\r\n", key, item.Flags, len(item.Data), item.CAS)
+		} else {
+			fmt.Fprintf(c.w, "VALUE %s %d %d\r\n", key, item.Flags, len(item.Data))
+		}
+		_, _ = c.w.Write(item.Data)
+		_, _ = c.w.WriteString("\r\n")
+	}
+
+	c.reply("END")
+}
+
+// store handles the storage commands:
+//
+//	set|add|replace <key> <flags> <exptime> <bytes> [noreply]
+//	cas <key> <flags> <exptime> <bytes> <cas unique> [noreply]
+func (s *Server) store(c *conn, args []string, mode storeMode) {
+	n := 4
+	if mode == modeCAS {
+		n = 5
+	}
+	if len(args) != n && !(len(args) == n+1 && noreply(args)) {
+		c.reply("ERROR")
+		return
+	}
+
+	key := args[0]
+	flags, flagsErr := strconv.ParseUint(args[1], 10, 32)
+	exptime, exptimeErr := strconv.ParseInt(args[2], 10, 64)
+	size, sizeErr := strconv.Atoi(args[3])
+	if !validKey(key) || flagsErr != nil || exptimeErr != nil || sizeErr != nil || size < 0 {
+		c.reply("CLIENT_ERROR bad command line format")
+		return
+	}
+
+	var casUnique uint64
+	if mode == modeCAS {
+		var err error
+		if casUnique, err = strconv.ParseUint(args[4], 10, 64); err != nil {
+			c.reply("CLIENT_ERROR bad command line format")
+			return
+		}
+	}
+
+	if size > maxItemSize {
+		// swallow the data block so that it is not parsed as a command
+		if _, err := c.r.Discard(size + 2); err != nil {
+			c.quit = true
+			return
+		}
+		c.reply("SERVER_ERROR object too large for cache")
+		return
+	}
+
+	data, ok, err := c.readData(size)
+	if err != nil {
+		c.quit = true
+		return
+	}
+	if !ok {
+		c.reply("CLIENT_ERROR bad data chunk")
+		return
+	}
+
+	s.stats.cmdSet.Add(1)
+
+	s.writeMutex.Lock()
+	result := s.storeItem(key, uint32(flags), exptime, data, mode, casUnique)
+	s.writeMutex.Unlock()
+
+	if !noreply(args) {
+		c.reply(result)
+	}
+}
+
+// storeItem must be called with writeMutex held. add and replace check that
+// the key exists with TTL, which neither counts a hit or a miss nor promotes
+// the key, only cas needs the old item.
+func (s *Server) storeItem(key string, flags uint32, exptime int64, data []byte, mode storeMode, casUnique uint64) string {
+	switch mode {
+	case modeAdd:
+		if _, exists := s.cache.TTL(key); exists {
+			return "NOT_STORED"
+		}
+	case modeReplace:
+		if _, exists := s.cache.TTL(key); !exists {
+			return "NOT_STORED"
+		}
+	case modeCAS:
+		old, exists := s.cache.Get(key)
+		if !exists {
+			s.stats.casMisses.Add(1)
+			return "NOT_FOUND"
+		}
+		if old.CAS != casUnique {
+			s.stats.casBadval.Add(1)
+			return "EXISTS"
+		}
+		s.stats.casHits.Add(1)
+	}
+
+	s.put(key, Item{
+		Flags: flags,
+		CAS:   s.lastCAS.Add(1),
+		Data:  data,
+	}, exptime)
+
+	return "STORED"
+}
+
+// put stores the item with memcached exptime semantics: 0 never expires,
+// even if the cache has a default TTL, up to 30 days is relative to now,
+// anything larger is a unix timestamp. Items that are already expired are
+// removed instead.
+func (s *Server) put(key string, item Item, exptime int64) {
+	switch {
+	case exptime == 0:
+		s.cache.AddWithTTL(key, item, lrucache.NoExpiration)
+	case exptime < 0:
+		s.cache.Remove(key)
+	case exptime <= maxRelativeExptime:
+		s.cache.AddWithTTL(key, item, time.Duration(exptime)*time.Second)
+	default:
+		ttl := time.Unix(exptime, 0).Sub(s.clock.Now())
+		if ttl <= 0 {
+			s.cache.Remove(key)
+			return
+		}
+		s.cache.AddWithTTL(key, item, ttl)
+	}
+}
+
+// delete handles "delete <key> [noreply]".
+func (s *Server) delete(c *conn, args []string) {
+	if len(args) != 1 && !(len(args) == 2 && noreply(args)) {
+		c.reply("CLIENT_ERROR bad command line format.  Usage: delete <key> [noreply]")
+		return
+	}
+	if !validKey(args[0]) {
+		c.reply("CLIENT_ERROR bad command line format")
+		return
+	}
+
+	s.writeMutex.Lock()
+	_, exists := s.cache.TTL(args[0])
+	if exists {
+		s.cache.Remove(args[0])
+	}
+	s.writeMutex.Unlock()
+
+	result := "DELETED"
+	if exists {
+		s.stats.deleteHits.Add(1)
+	} else {
+		s.stats.deleteMisses.Add(1)
+		result = "NOT_FOUND"
+	}
+
+	if !noreply(args) {
+		c.reply(result)
+	}
+}
+
+// touch handles "touch <key> <exptime> [noreply]".
+func (s *Server) touch(c *conn, args []string) {
+	if len(args) != 2 && !(len(args) == 3 && noreply(args)) {
+		c.reply("ERROR")
+		return
+	}
+
+	exptime, err := strconv.ParseInt(args[1], 10, 64)
+	if !validKey(args[0]) || err != nil {
+		c.reply("CLIENT_ERROR bad command line format")
+		return
+	}
+
+	s.stats.cmdTouch.Add(1)
+
+	s.writeMutex.Lock()
+	item, exists := s.cache.Get(args[0])
+	if exists {
+		s.put(args[0], item, exptime)
+	}
+	s.writeMutex.Unlock()
+
+	result := "TOUCHED"
+	if exists {
+		s.stats.touchHits.Add(1)
+	} else {
+		s.stats.touchMisses.Add(1)
+		result = "NOT_FOUND"
+	}
+
+	if !noreply(args) {
+		c.reply(result)
+	}
+}
+
+// flushAll handles "flush_all [delay] [noreply]".
+func (s *Server) flushAll(c *conn, args []string) {
+	var delay int64
+	if len(args) > 0 && !(len(args) == 1 && noreply(args)) {
+		var err error
+		if delay, err = strconv.ParseInt(args[0], 10, 64); err != nil || delay < 0 || len(args) > 2 {
+			c.reply("CLIENT_ERROR bad command line format")
+			return
+		}
+	}
+
+	s.stats.cmdFlush.Add(1)
+
+	s.writeMutex.Lock()
+	s.cancelFlush()
+	if delay == 0 {
+		s.cache.Clear()
+	} else {
+		cancel := make(chan struct{})
+		s.flushCancel = cancel
+		after := s.clock.After(time.Duration(delay) * time.Second)
+		go func() {
+			select {
+			case <-after:
+			case <-cancel:
+				return
+			}
+
+			s.writeMutex.Lock()
+			defer s.writeMutex.Unlock()
+
+			// the flush may have been canceled while waiting for the lock
+			select {
+			case <-cancel:
+			default:
+				s.cache.Clear()
+				s.flushCancel = nil
+			}
+		}()
+	}
+	s.writeMutex.Unlock()
+
+	if !noreply(args) {
+		c.reply("OK")
+	}
+}
+
+// cancelFlush cancels a pending delayed flush_all. Must be called with
+// writeMutex held.
+func (s *Server) cancelFlush() {
+	if s.flushCancel != nil {
+		close(s.flushCancel)
+		s.flushCancel = nil
+	}
+}
+
+func (s *Server) writeStats(c *conn, args []string) {
+	if len(args) > 0 {
+		// only general-purpose statistics are supported
+		c.reply("ERROR")
+		return
+	}
+
+	now := s.clock.Now()
+	stat := func(name string, value any) {
+		fmt.Fprintf(c.w, "STAT %s %v\r\n", name, value)
+	}
+
+	stat("pid", os.Getpid())
+	stat("uptime", int64(now.Sub(s.started).Seconds()))
+	stat("time", now.Unix())
+	stat("version", version)
+	stat("curr_items", s.cache.Len())
+	stat("limit_items", s.cache.Cap())
+	stat("cmd_get", s.stats.cmdGet.Load())
+	stat("cmd_set", s.stats.cmdSet.Load())
+	stat("cmd_flush", s.stats.cmdFlush.Load())
+	stat("cmd_touch", s.stats.cmdTouch.Load())
+	stat("get_hits", s.stats.getHits.Load())
+	stat("get_misses", s.stats.getMisses.Load())
+	stat("delete_misses", s.stats.deleteMisses.Load())
+	stat("delete_hits", s.stats.deleteHits.Load())
+	stat("cas_misses", s.stats.casMisses.Load())
+	stat("cas_hits", s.stats.casHits.Load())
+	stat("cas_badval", s.stats.casBadval.Load())
+	stat("touch_hits", s.stats.touchHits.Load())
+	stat("touch_misses", s.stats.touchMisses.Load())
+
+	c.reply("END")
+}
+
+func noreply(args []string) bool {
+	return len(args) > 0 && args[len(args)-1] == "noreply"
+}
+
+func validKey(key string) bool {
+	if len(key) == 0 || len(key) > maxKeyLen {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
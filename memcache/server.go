@@ -0,0 +1,182 @@
+// Package memcache exposes a cache to memcached clients over the memcached
+// text protocol.
+package memcache
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/bemmanue/LRUCacheService/internal/tcpserver"
+)
+
+const (
+	maxLineLen  = 2048
+	maxKeyLen   = 250
+	maxItemSize = 1 << 20
+)
+
+var ErrServerClosed = tcpserver.ErrServerClosed
+
+var errLineTooLong = errors.New("line too long")
+
+// Item is a cached value together with the metadata memcached keeps for it.
+type Item struct {
+	Flags uint32
+	CAS   uint64
+	Data  []byte
+}
+
+type Server struct {
+	cache   lrucache.ICacheWithTTL[string, Item]
+	clock   lrucache.Clock
+	started time.Time
+	tcp     tcpserver.Server
+
+	// writeMutex serializes commands that read an item before storing it
+	// (add, replace, cas, touch) with all other writes
+	writeMutex  sync.Mutex
+	lastCAS     atomic.Uint64
+	flushCancel chan struct{}
+
+	stats stats
+}
+
+type stats struct {
+	cmdGet       atomic.Uint64
+	cmdSet       atomic.Uint64
+	cmdTouch     atomic.Uint64
+	cmdFlush     atomic.Uint64
+	getHits      atomic.Uint64
+	getMisses    atomic.Uint64
+	deleteHits   atomic.Uint64
+	deleteMisses atomic.Uint64
+	touchHits    atomic.Uint64
+	touchMisses  atomic.Uint64
+	casHits      atomic.Uint64
+	casMisses    atomic.Uint64
+	casBadval    atomic.Uint64
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithClock sets the clock used for absolute exptimes, delayed flush_all and
+// stats. It should be the clock of the cache, the system clock by default.
+func WithClock(clock lrucache.Clock) Option {
+	return func(s *Server) {
+		s.clock = clock
+	}
+}
+
+func NewServer(cache lrucache.ICacheWithTTL[string, Item], opts ...Option) *Server {
+	s := &Server{
+		cache: cache,
+		clock: lrucache.SystemClock{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.started = s.clock.Now()
+	s.tcp.Handler = s.serveConn
+
+	return s
+}
+
+// Serve accepts connections on l until Close is called, in which case it
+// returns ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	return s.tcp.Serve(l)
+}
+
+// Close stops all listeners, closes open connections and cancels a pending
+// delayed flush_all.
+func (s *Server) Close() error {
+	s.writeMutex.Lock()
+	s.cancelFlush()
+	s.writeMutex.Unlock()
+
+	return s.tcp.Close()
+}
+
+type conn struct {
+	r    *bufio.Reader
+	w    *bufio.Writer
+	quit bool
+}
+
+func (s *Server) serveConn(netConn net.Conn) {
+	c := &conn{
+		r: bufio.NewReaderSize(netConn, maxLineLen),
+		w: bufio.NewWriter(netConn),
+	}
+
+	for !c.quit {
+		line, err := c.readLine()
+		if err != nil {
+			if errors.Is(err, errLineTooLong) {
+				_, _ = c.w.WriteString("CLIENT_ERROR line too long\r\n")
+				_ = c.w.Flush()
+			}
+			return
+		}
+
+		s.exec(c, line)
+
+		// pipelined commands are answered in a single write
+		if c.r.Buffered() == 0 || c.quit {
+			if err := c.w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLine reads a command line terminated by "\r\n" or "\n".
+func (c *conn) readLine() (string, error) {
+	line, err := c.r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", errLineTooLong
+	}
+	if err != nil {
+		if len(line) > 0 && errors.Is(err, io.EOF) {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+
+	line = line[:len(line)-1]
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return string(line), nil
+}
+
+// readData reads a data block of n bytes followed by "\r\n". ok is false if
+// the block is not terminated correctly.
+func (c *conn) readData(n int) (data []byte, ok bool, err error) {
+	data = make([]byte, n+2)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return nil, false, err
+	}
+	if data[n] != '\r' || data[n+1] != '\n' {
+		// skip the rest of the line the same way memcached does
+		if data[n+1] != '\n' {
+			if _, err := c.r.ReadSlice('\n'); err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+				return nil, false, err
+			}
+		}
+		return nil, false, nil
+	}
+	return data[:n], true, nil
+}
+
+func (c *conn) reply(s string) {
+	_, _ = c.w.WriteString(s)
+	_, _ = c.w.WriteString("\r\n")
+}
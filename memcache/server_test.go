@@ -0,0 +1,390 @@
+package memcache
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// newTestServer starts a server whose cache and server share a FakeClock.
+func newTestServer(t *testing.T, cap int, opts ...lrucache.Option[string, Item]) (*lrucache.CacheWithTTL2[string, Item], *lrucache.FakeClock, string) {
+	clock := lrucache.NewFakeClock(time.Now())
+	cache := lrucache.NewWithTTL2[string, Item](cap, append(opts, lrucache.WithClock[string, Item](clock))...)
+	srv := NewServer(cache, WithClock(clock))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(l)
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, srv.Close())
+		assert.ErrorIs(t, <-done, ErrServerClosed)
+	})
+
+	return cache, clock, l.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *testConn {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	return &testConn{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *testConn) send(request string) {
+	_, err := c.conn.Write([]byte(request))
+	require.NoError(c.t, err)
+}
+
+// expect reads len(expected) bytes and compares them with expected.
+func (c *testConn) expect(expected string) {
+	buf := make([]byte, len(expected))
+	_, err := io.ReadFull(c.r, buf)
+	require.NoError(c.t, err)
+	assert.Equal(c.t, expected, string(buf))
+}
+
+// readUntilEnd reads the lines of a multi-line reply up to and including "END".
+func (c *testConn) readUntilEnd() []string {
+	var lines []string
+	for {
+		line, err := c.r.ReadString('\n')
+		require.NoError(c.t, err)
+
+		line = strings.TrimSuffix(line, "\r\n")
+		lines = append(lines, line)
+		if line == "END" {
+			return lines
+		}
+	}
+}
+
+func (c *testConn) cas(key string) uint64 {
+	c.send("gets " + key + "\r\n")
+	lines := c.readUntilEnd()
+	require.Len(c.t, lines, 3)
+
+	fields := strings.Fields(lines[0])
+	require.Len(c.t, fields, 5)
+
+	cas, err := strconv.ParseUint(fields[4], 10, 64)
+	require.NoError(c.t, err)
+	return cas
+}
+
+func Test_Server_Conformance(t *testing.T) {
+	cases := []struct {
+		name  string
+		steps [][2]string // request, expected reply
+	}{
+		{
+			name: "set and get",
+			steps: [][2]string{
+				{"set key 5 0 5\r\nvalue\r\n", "STORED\r\n"},
+				{"get key\r\n", "VALUE key 5 5\r\nvalue\r\nEND\r\n"},
+				{"get missing\r\n", "END\r\n"},
+			},
+		},
+		{
+			name: "get multiple keys",
+			steps: [][2]string{
+				{"set a 0 0 1\r\n1\r\n", "STORED\r\n"},
+				{"set b 1 0 2\r\n22\r\n", "STORED\r\n"},
+				{"get a missing b\r\n", "VALUE a 0 1\r\n1\r\nVALUE b 1 2\r\n22\r\nEND\r\n"},
+			},
+		},
+		{
+			name: "binary data",
+			steps: [][2]string{
+				{"set key 0 0 4\r\na\r\nb\r\n", "STORED\r\n"},
+				{"get key\r\n", "VALUE key 0 4\r\na\r\nb\r\nEND\r\n"},
+				{"set empty 0 0 0\r\n\r\n", "STORED\r\n"},
+				{"get empty\r\n", "VALUE empty 0 0\r\n\r\nEND\r\n"},
+			},
+		},
+		{
+			name: "add",
+			steps: [][2]string{
+				{"add key 0 0 1\r\na\r\n", "STORED\r\n"},
+				{"add key 0 0 1\r\nb\r\n", "NOT_STORED\r\n"},
+				{"get key\r\n", "VALUE key 0 1\r\na\r\nEND\r\n"},
+			},
+		},
+		{
+			name: "replace",
+			steps: [][2]string{
+				{"replace key 0 0 1\r\na\r\n", "NOT_STORED\r\n"},
+				{"set key 0 0 1\r\na\r\n", "STORED\r\n"},
+				{"replace key 0 0 1\r\nb\r\n", "STORED\r\n"},
+				{"get key\r\n", "VALUE key 0 1\r\nb\r\nEND\r\n"},
+			},
+		},
+		{
+			name: "delete",
+			steps: [][2]string{
+				{"delete key\r\n", "NOT_FOUND\r\n"},
+				{"set key 0 0 1\r\na\r\n", "STORED\r\n"},
+				{"delete key\r\n", "DELETED\r\n"},
+				{"get key\r\n", "END\r\n"},
+			},
+		},
+		{
+			name: "touch",
+			steps: [][2]string{
+				{"touch key 10\r\n", "NOT_FOUND\r\n"},
+				{"set key 0 0 1\r\na\r\n", "STORED\r\n"},
+				{"touch key 10\r\n", "TOUCHED\r\n"},
+				{"touch key -1\r\n", "TOUCHED\r\n"},
+				{"get key\r\n", "END\r\n"},
+			},
+		},
+		{
+			name: "negative exptime",
+			steps: [][2]string{
+				{"set key 0 0 1\r\na\r\n", "STORED\r\n"},
+				{"set key 0 -1 1\r\nb\r\n", "STORED\r\n"},
+				{"get key\r\n", "END\r\n"},
+			},
+		},
+		{
+			name: "past unix timestamp",
+			steps: [][2]string{
+				{"set key 0 2592001 1\r\na\r\n", "STORED\r\n"},
+				{"get key\r\n", "END\r\n"},
+			},
+		},
+		{
+			name: "noreply",
+			steps: [][2]string{
+				{"set key 0 0 1 noreply\r\na\r\n", ""},
+				{"add key 0 0 1 noreply\r\nb\r\n", ""},
+				{"touch key 10 noreply\r\n", ""},
+				{"get key\r\n", "VALUE key 0 1\r\na\r\nEND\r\n"},
+				{"delete key noreply\r\n", ""},
+				{"flush_all noreply\r\n", ""},
+				{"get key\r\n", "END\r\n"},
+			},
+		},
+		{
+			name: "flush_all",
+			steps: [][2]string{
+				{"set a 0 0 1\r\n1\r\n", "STORED\r\n"},
+				{"set b 0 0 1\r\n2\r\n", "STORED\r\n"},
+				{"flush_all\r\n", "OK\r\n"},
+				{"get a b\r\n", "END\r\n"},
+			},
+		},
+		{
+			name: "pipelining",
+			steps: [][2]string{
+				{"set a 0 0 1\r\n1\r\nget a\r\ndelete a\r\n", "STORED\r\nVALUE a 0 1\r\n1\r\nEND\r\nDELETED\r\n"},
+			},
+		},
+		{
+			name: "errors",
+			steps: [][2]string{
+				{"\r\n", "ERROR\r\n"},
+				{"unknown\r\n", "ERROR\r\n"},
+				{"get\r\n", "ERROR\r\n"},
+				{"set key 0 0\r\n", "ERROR\r\n"},
+				{"set key x 0 1\r\n", "CLIENT_ERROR bad command line format\r\n"},
+				{"get " + strings.Repeat("k", maxKeyLen+1) + "\r\n", "CLIENT_ERROR bad command line format\r\n"},
+				{"set key 0 0 1\r\nab\r\n", "CLIENT_ERROR bad data chunk\r\n"},
+				{"set big 0 0 " + strconv.Itoa(maxItemSize+1) + "\r\n" + strings.Repeat("x", maxItemSize+1) + "\r\n", "SERVER_ERROR object too large for cache\r\n"},
+				{"get big\r\n", "END\r\n"},
+			},
+		},
+		{
+			name: "version",
+			steps: [][2]string{
+				{"version\r\n", "VERSION " + version + "\r\n"},
+				{"verbosity 1\r\n", "OK\r\n"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, addr := newTestServer(t, 10)
+			conn := dial(t, addr)
+
+			for _, step := range c.steps {
+				conn.send(step[0])
+				conn.expect(step[1])
+			}
+		})
+	}
+}
+
+func Test_Server_CAS(t *testing.T) {
+	_, _, addr := newTestServer(t, 10)
+	c := dial(t, addr)
+
+	c.send("cas key 0 0 1 1\r\na\r\n")
+	c.expect("NOT_FOUND\r\n")
+
+	c.send("set key 0 0 1\r\na\r\n")
+	c.expect("STORED\r\n")
+
+	cas := c.cas("key")
+
+	c.send("cas key 0 0 1 " + strconv.FormatUint(cas+1, 10) + "\r\nb\r\n")
+	c.expect("EXISTS\r\n")
+
+	c.send("cas key 0 0 1 " + strconv.FormatUint(cas, 10) + "\r\nb\r\n")
+	c.expect("STORED\r\n")
+
+	assert.NotEqual(t, cas, c.cas("key"))
+
+	c.send("get key\r\n")
+	c.expect("VALUE key 0 1\r\nb\r\nEND\r\n")
+}
+
+func Test_Server_Expiration(t *testing.T) {
+	cache, clock, addr := newTestServer(t, 10)
+	c := dial(t, addr)
+
+	c.send("set relative 0 100 1\r\na\r\n")
+	c.expect("STORED\r\n")
+
+	ttl, ok := cache.TTL("relative")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 100*time.Second, ttl)
+
+	exptime := clock.Now().Add(time.Hour).Unix()
+	c.send("set absolute 0 " + strconv.FormatInt(exptime, 10) + " 1\r\na\r\n")
+	c.expect("STORED\r\n")
+
+	ttl, ok = cache.TTL("absolute")
+	assert.Equal(t, true, ok)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Second))
+
+	c.send("set forever 0 0 1\r\na\r\n")
+	c.expect("STORED\r\n")
+
+	ttl, ok = cache.TTL("forever")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, lrucache.NoExpiration, ttl)
+
+	c.send("touch forever 1\r\n")
+	c.expect("TOUCHED\r\n")
+
+	clock.Advance(time.Second + time.Millisecond)
+	_, ok = cache.Get("forever")
+	assert.False(t, ok)
+}
+
+func Test_Server_DefaultTTL(t *testing.T) {
+	cache, _, addr := newTestServer(t, 10, lrucache.WithDefaultTTL[string, Item](time.Minute))
+	c := dial(t, addr)
+
+	// exptime 0 never expires, regardless of the default TTL
+	c.send("set forever 0 0 1\r\na\r\n")
+	c.expect("STORED\r\n")
+
+	ttl, ok := cache.TTL("forever")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, lrucache.NoExpiration, ttl)
+}
+
+func Test_Server_DelayedFlush(t *testing.T) {
+	cache, clock, addr := newTestServer(t, 10)
+	c := dial(t, addr)
+
+	c.send("set key 0 0 1\r\na\r\n")
+	c.expect("STORED\r\n")
+
+	c.send("flush_all 1\r\n")
+	c.expect("OK\r\n")
+	assert.Equal(t, 1, cache.Len())
+
+	clock.Advance(time.Second - time.Millisecond)
+	assert.Equal(t, 1, cache.Len())
+
+	clock.Advance(time.Millisecond)
+	require.Eventually(t, func() bool {
+		return cache.Len() == 0
+	}, time.Second, time.Millisecond)
+}
+
+func Test_Server_StoreStats(t *testing.T) {
+	cache, _, addr := newTestServer(t, 10)
+	c := dial(t, addr)
+
+	// only get, gets and cas read the item
+	c.send("add key 0 0 1\r\na\r\n")
+	c.expect("STORED\r\n")
+	c.send("add key 0 0 1\r\nb\r\n")
+	c.expect("NOT_STORED\r\n")
+	c.send("replace key 0 0 1\r\nc\r\n")
+	c.expect("STORED\r\n")
+	c.send("replace other 0 0 1\r\nc\r\n")
+	c.expect("NOT_STORED\r\n")
+	c.send("delete key\r\n")
+	c.expect("DELETED\r\n")
+	c.send("delete key\r\n")
+	c.expect("NOT_FOUND\r\n")
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(0), stats.Hits)
+	assert.Equal(t, uint64(0), stats.Misses)
+}
+
+func Test_Server_Stats(t *testing.T) {
+	_, _, addr := newTestServer(t, 10)
+	c := dial(t, addr)
+
+	c.send("set key 0 0 1\r\na\r\n")
+	c.expect("STORED\r\n")
+	c.send("get key missing\r\n")
+	c.expect("VALUE key 0 1\r\na\r\nEND\r\n")
+
+	c.send("stats\r\n")
+	lines := c.readUntilEnd()
+
+	stats := map[string]string{}
+	for _, line := range lines[:len(lines)-1] {
+		require.Regexp(t, regexp.MustCompile(`^STAT \S+ \S+$`), line)
+		fields := strings.Fields(line)
+		stats[fields[1]] = fields[2]
+	}
+
+	assert.Equal(t, "1", stats["curr_items"])
+	assert.Equal(t, "10", stats["limit_items"])
+	assert.Equal(t, "2", stats["cmd_get"])
+	assert.Equal(t, "1", stats["cmd_set"])
+	assert.Equal(t, "1", stats["get_hits"])
+	assert.Equal(t, "1", stats["get_misses"])
+}
+
+func Test_Server_Quit(t *testing.T) {
+	_, _, addr := newTestServer(t, 10)
+	c := dial(t, addr)
+
+	c.send("quit\r\n")
+
+	_, err := c.r.ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+}
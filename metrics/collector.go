@@ -0,0 +1,217 @@
+// Package metrics exports cache statistics in the Prometheus text exposition
+// format and in OpenMetrics without depending on the Prometheus client.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+)
+
+const (
+	textContentType        = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Source is implemented by every cache of the lrucache package.
+type Source = lrucache.StatsSource
+
+// Collector can be passed to lrucache.WithMetrics.
+var _ lrucache.MetricsRegistry = (*Collector)(nil)
+
+type metricType string
+
+const (
+	gauge   metricType = "gauge"
+	counter metricType = "counter"
+)
+
+type metric struct {
+	name  string // name of the family, counters get the "_total" suffix on samples
+	typ   metricType
+	help  string
+	value func(s Source, stats lrucache.Stats) uint64
+}
+
+var cacheMetrics = []metric{
+	{
+		name:  "lrucache_entries",
+		typ:   gauge,
+		help:  "Number of elements in the cache.",
+		value: func(s Source, _ lrucache.Stats) uint64 { return uint64(s.Len()) },
+	},
+	{
+		name:  "lrucache_capacity",
+		typ:   gauge,
+		help:  "Maximum number of elements in the cache.",
+		value: func(s Source, _ lrucache.Stats) uint64 { return uint64(s.Cap()) },
+	},
+	{
+		name:  "lrucache_hits",
+		typ:   counter,
+		help:  "Get calls that found a value.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.Hits },
+	},
+	{
+		name:  "lrucache_misses",
+		typ:   counter,
+		help:  "Get calls that found no value.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.Misses },
+	},
+	{
+		name:  "lrucache_inserts",
+		typ:   counter,
+		help:  "New keys added to the cache.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.Inserts },
+	},
+	{
+		name:  "lrucache_updates",
+		typ:   counter,
+		help:  "Values replaced for existing keys.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.Updates },
+	},
+	{
+		name:  "lrucache_evictions",
+		typ:   counter,
+		help:  "Elements displaced because the cache was full.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.Evictions },
+	},
+	{
+		name:  "lrucache_expirations",
+		typ:   counter,
+		help:  "Elements removed because their TTL has passed.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.Expirations },
+	},
+	{
+		name:  "lrucache_removals",
+		typ:   counter,
+		help:  "Elements removed explicitly.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.Removals },
+	},
+	{
+		name:  "lrucache_negative_hits",
+		typ:   counter,
+		help:  "Lookups that found a negative entry.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.NegativeHits },
+	},
+	{
+		name:  "lrucache_negative_inserts",
+		typ:   counter,
+		help:  "Negative entries added to the cache.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.NegativeInserts },
+	},
+	{
+		name:  "lrucache_rejections",
+		typ:   counter,
+		help:  "Values not added as too large or not admitted to the full cache.",
+		value: func(_ Source, stats lrucache.Stats) uint64 { return stats.Rejections },
+	},
+}
+
+// Collector exports metrics of registered caches, each sample is labelled
+// with the name the cache was registered under.
+type Collector struct {
+	mutex  sync.RWMutex
+	caches map[string]Source
+}
+
+func NewCollector() *Collector {
+	return &Collector{caches: make(map[string]Source)}
+}
+
+// Register adds a cache under a unique name.
+func (c *Collector) Register(name string, cache Source) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.caches[name]; ok {
+		return fmt.Errorf("metrics: cache %q is already registered", name)
+	}
+	c.caches[name] = cache
+
+	return nil
+}
+
+func (c *Collector) Unregister(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.caches, name)
+}
+
+// WriteText writes the metrics in the Prometheus text exposition format.
+func (c *Collector) WriteText(w io.Writer) error {
+	return c.write(w, false)
+}
+
+// WriteOpenMetrics writes the metrics in the OpenMetrics text format.
+func (c *Collector) WriteOpenMetrics(w io.Writer) error {
+	return c.write(w, true)
+}
+
+// ServeHTTP serves the metrics in OpenMetrics if the client accepts it and in
+// the Prometheus text format otherwise.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+
+	if openMetrics {
+		w.Header().Set("Content-Type", openMetricsContentType)
+	} else {
+		w.Header().Set("Content-Type", textContentType)
+	}
+
+	_ = c.write(w, openMetrics)
+}
+
+func (c *Collector) write(w io.Writer, openMetrics bool) error {
+	type sample struct {
+		name  string
+		cache Source
+		stats lrucache.Stats
+	}
+
+	c.mutex.RLock()
+	samples := make([]sample, 0, len(c.caches))
+	for name, cache := range c.caches {
+		samples = append(samples, sample{name: name, cache: cache, stats: cache.Stats()})
+	}
+	c.mutex.RUnlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].name < samples[j].name
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, m := range cacheMetrics {
+		family, sampleName := m.name, m.name
+		if m.typ == counter {
+			sampleName += "_total"
+			if !openMetrics {
+				family = sampleName
+			}
+		}
+
+		fmt.Fprintf(bw, "# HELP %s %s\n", family, m.help)
+		fmt.Fprintf(bw, "# TYPE %s %s\n", family, m.typ)
+		for _, s := range samples {
+			fmt.Fprintf(bw, "%s{cache=\"%s\"} %d\n", sampleName, escapeLabel(s.name), m.value(s.cache, s.stats))
+		}
+	}
+	if openMetrics {
+		fmt.Fprint(bw, "# EOF\n")
+	}
+
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCollector(t *testing.T) *Collector {
+	users := lrucache.New[string, int](2)
+	users.Add("first", 1)
+	users.Add("second", 2)
+	users.Add("third", 3)
+	users.Get("third")
+	users.Get("first")
+
+	sessions := lrucache.NewWithTTL2[int, string](10)
+	sessions.Add(1, "one")
+	sessions.Remove(1)
+
+	c := NewCollector()
+	require.NoError(t, c.Register("users", users))
+	require.NoError(t, c.Register("sessions", sessions))
+
+	return c
+}
+
+func Test_Collector_WriteText(t *testing.T) {
+	c := newTestCollector(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.WriteText(&buf))
+
+	assert.Equal(t, `# HELP lrucache_entries Number of elements in the cache.
+# TYPE lrucache_entries gauge
+lrucache_entries{cache="sessions"} 0
+lrucache_entries{cache="users"} 2
+# HELP lrucache_capacity Maximum number of elements in the cache.
+# TYPE lrucache_capacity gauge
+lrucache_capacity{cache="sessions"} 10
+lrucache_capacity{cache="users"} 2
+# HELP lrucache_hits_total Get calls that found a value.
+# TYPE lrucache_hits_total counter
+lrucache_hits_total{cache="sessions"} 0
+lrucache_hits_total{cache="users"} 1
+# HELP lrucache_misses_total Get calls that found no value.
+# TYPE lrucache_misses_total counter
+lrucache_misses_total{cache="sessions"} 0
+lrucache_misses_total{cache="users"} 1
+# HELP lrucache_inserts_total New keys added to the cache.
+# TYPE lrucache_inserts_total counter
+lrucache_inserts_total{cache="sessions"} 1
+lrucache_inserts_total{cache="users"} 3
+# HELP lrucache_updates_total Values replaced for existing keys.
+# TYPE lrucache_updates_total counter
+lrucache_updates_total{cache="sessions"} 0
+lrucache_updates_total{cache="users"} 0
+# HELP lrucache_evictions_total Elements displaced because the cache was full.
+# TYPE lrucache_evictions_total counter
+lrucache_evictions_total{cache="sessions"} 0
+lrucache_evictions_total{cache="users"} 1
+# HELP lrucache_expirations_total Elements removed because their TTL has passed.
+# TYPE lrucache_expirations_total counter
+lrucache_expirations_total{cache="sessions"} 0
+lrucache_expirations_total{cache="users"} 0
+# HELP lrucache_removals_total Elements removed explicitly.
+# TYPE lrucache_removals_total counter
+lrucache_removals_total{cache="sessions"} 1
+lrucache_removals_total{cache="users"} 0
+# HELP lrucache_negative_hits_total Lookups that found a negative entry.
+# TYPE lrucache_negative_hits_total counter
+lrucache_negative_hits_total{cache="sessions"} 0
+lrucache_negative_hits_total{cache="users"} 0
+# HELP lrucache_negative_inserts_total Negative entries added to the cache.
+# TYPE lrucache_negative_inserts_total counter
+lrucache_negative_inserts_total{cache="sessions"} 0
+lrucache_negative_inserts_total{cache="users"} 0
+# HELP lrucache_rejections_total Values not added as too large or not admitted to the full cache.
+# TYPE lrucache_rejections_total counter
+lrucache_rejections_total{cache="sessions"} 0
+lrucache_rejections_total{cache="users"} 0
+`, buf.String())
+}
+
+func Test_Collector_WriteOpenMetrics(t *testing.T) {
+	c := newTestCollector(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.WriteOpenMetrics(&buf))
+
+	out := buf.String()
+	assert.Contains(t, out, "# HELP lrucache_hits Get calls that found a value.\n# TYPE lrucache_hits counter\nlrucache_hits_total{cache=\"sessions\"} 0\n")
+	assert.Contains(t, out, "# TYPE lrucache_entries gauge\nlrucache_entries{cache=\"sessions\"} 0\n")
+	assert.True(t, strings.HasSuffix(out, "\n# EOF\n"))
+}
+
+func Test_Collector_Register(t *testing.T) {
+	c := NewCollector()
+	cache := lrucache.New[string, int](1)
+
+	require.NoError(t, c.Register("cache", cache))
+	assert.Error(t, c.Register("cache", cache))
+
+	c.Unregister("cache")
+	require.NoError(t, c.Register("cache", cache))
+}
+
+func Test_Collector_EscapesLabels(t *testing.T) {
+	c := NewCollector()
+	require.NoError(t, c.Register("a\"b\\c\nd", lrucache.New[string, int](1)))
+
+	var buf bytes.Buffer
+	require.NoError(t, c.WriteText(&buf))
+
+	assert.Contains(t, buf.String(), `lrucache_entries{cache="a\"b\\c\nd"} 0`)
+}
+
+func Test_Collector_ServeHTTP(t *testing.T) {
+	srv := httptest.NewServer(newTestCollector(t))
+	defer srv.Close()
+
+	cases := []struct {
+		name        string
+		accept      string
+		contentType string
+		eof         bool
+	}{
+		{
+			name:        "prometheus text",
+			contentType: textContentType,
+		},
+		{
+			name:        "openmetrics",
+			accept:      "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5",
+			contentType: openMetricsContentType,
+			eof:         true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+			if c.accept != "" {
+				req.Header.Set("Accept", c.accept)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, c.contentType, resp.Header.Get("Content-Type"))
+			assert.Contains(t, string(body), `lrucache_entries{cache="users"} 2`)
+			assert.Equal(t, c.eof, strings.HasSuffix(string(body), "# EOF\n"))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package lrucache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound can be returned by a Loader to report that the key has no
+// value. It is also stored by AddNegative when no error is given.
+var ErrNotFound = errors.New("lrucache: not found")
+
+// AddNegative remembers for ttl that the key has no value, err tells why.
+// Get reports negative entries as missing and Lookup returns their error.
+// They take up capacity like any other element.
+func (c *CacheWithTTL[K, V]) AddNegative(key K, err error, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.unlock()
+
+	if elem := c.addNegative(key, negativeError(err)); elem != nil {
+		c.setExpiration(elem, c.clock.Now().Add(ttl))
+	}
+}
+
+// Lookup returns the value of the key, or the error of a negative entry.
+// ok is false if the key is not cached.
+func (c *CacheWithTTL[K, V]) Lookup(key K) (value V, ok bool, err error) {
+	return c.lookup(key)
+}
+
+// AddNegative remembers for ttl that the key has no value, err tells why.
+// Get reports negative entries as missing and Lookup returns their error.
+// They take up capacity like any other element.
+func (c *CacheWithTTL2[K, V]) AddNegative(key K, err error, ttl time.Duration) {
+	c.UpdateExpirations()
+
+	c.mutex.Lock()
+	defer c.unlock()
+
+	if elem := c.addNegative(key, negativeError(err)); elem != nil {
+		c.setExpiration(elem, c.clock.Now().Add(ttl))
+	}
+}
+
+// Lookup returns the value of the key, or the error of a negative entry.
+// ok is false if the key is not cached.
+func (c *CacheWithTTL2[K, V]) Lookup(key K) (value V, ok bool, err error) {
+	c.UpdateExpirations()
+
+	return c.lookup(key)
+}
+
+func negativeError(err error) error {
+	if err == nil {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package lrucache
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type negativeCache interface {
+	ICacheWithTTL[string, any]
+	AddNegative(key string, err error, ttl time.Duration)
+	Lookup(key string) (value any, ok bool, err error)
+	Stats() Stats
+}
+
+func Test_AddNegative(t *testing.T) {
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			var rec evictionRecorder
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 2, rec.option(), WithClock[string, any](clock)).(negativeCache)
+
+			cache.AddNegative("missing", nil, time.Millisecond*30)
+			assert.Equal(t, 1, cache.Len())
+
+			value, ok := cache.Get("missing")
+			assert.False(t, ok)
+			assert.Nil(t, value)
+
+			_, ok, err := cache.Lookup("missing")
+			assert.True(t, ok)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			_, ok = cache.TTL("missing")
+			assert.False(t, ok)
+
+			assert.Equal(t, Stats{NegativeHits: 2, NegativeInserts: 1}, cache.Stats())
+
+			advance(t, clock, time.Millisecond*40)
+			_, ok, _ = cache.Lookup("missing")
+			assert.False(t, ok)
+
+			// a value replaces a negative entry and the other way around
+			backendErr := errors.New("backend is down")
+			cache.AddNegative("key", backendErr, time.Minute)
+			cache.Add("key", 1)
+
+			value, ok, err = cache.Lookup("key")
+			assert.True(t, ok)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, value)
+
+			cache.AddNegative("key", backendErr, time.Minute)
+			_, ok, err = cache.Lookup("key")
+			assert.True(t, ok)
+			assert.ErrorIs(t, err, backendErr)
+
+			// the hook only sees real values
+			assert.Equal(t, []eviction[string, any]{{"key", 1, EvictReasonReplaced}}, rec.get())
+		})
+	}
+}
+
+func Test_GetOrLoad_NegativeTTL(t *testing.T) {
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 10, WithNegativeTTL[string, any](time.Millisecond*30), WithClock[string, any](clock)).(negativeCache)
+			loading := cache.(loadingCache)
+
+			var calls int
+			loader := func(ctx context.Context, key string) (any, time.Duration, error) {
+				calls++
+				if calls == 1 {
+					return nil, 0, ErrNotFound
+				}
+				return "found", 0, nil
+			}
+
+			_, err := loading.GetOrLoad(context.Background(), "key", loader)
+			assert.ErrorIs(t, err, ErrNotFound)
+			_, err = loading.GetOrLoad(context.Background(), "key", loader)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Equal(t, 1, calls)
+
+			advance(t, clock, time.Millisecond*40)
+			value, err := loading.GetOrLoad(context.Background(), "key", loader)
+			assert.NoError(t, err)
+			assert.Equal(t, "found", value)
+			assert.Equal(t, 2, calls)
+
+			// context errors are not remembered
+			_, err = loading.GetOrLoad(context.Background(), "other", func(ctx context.Context, key string) (any, time.Duration, error) {
+				return nil, 0, context.Canceled
+			})
+			assert.ErrorIs(t, err, context.Canceled)
+			_, ok, _ := cache.Lookup("other")
+			assert.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package lrucache
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures a cache on construction.
+type Option[K comparable, V any] func(*options[K, V])
+
+type options[K comparable, V any] struct {
+	cap        int
+	background bool
+	gcInterval time.Duration
+
+	metrics     MetricsRegistry
+	metricsName string
+
+	onEvict     func(key K, value V, reason EvictReason)
+	negativeTTL time.Duration
+
+	loader             Loader[K, V]
+	staleTTL           time.Duration
+	refreshAhead       time.Duration
+	refreshConcurrency int
+	refreshCtx         context.Context
+
+	sliding     bool
+	maxLifetime time.Duration
+
+	defaultTTL time.Duration
+	ttlRules   []TTLRule
+	ttlRule    func(key K) (ttl time.Duration, ok bool)
+
+	jitterFraction float64
+	jitterMin      time.Duration
+	jitterMax      time.Duration
+	jitterSeed     int64
+	jitterSeeded   bool
+
+	sharded bool
+	shards  int
+	hash    func(key K) uint64
+
+	newPolicy func(cap int) EvictionPolicy[K, V]
+	tinyLFU   bool
+
+	maxCost int64
+	sizer   Sizer[K, V]
+
+	timingWheel bool
+
+	clock Clock
+}
+
+// WithOnEvict sets a hook that is called with every element that leaves the
+// cache and the reason it left. The hook is called after the cache lock is
+// released, in the goroutine that caused the eviction.
+func WithOnEvict[K comparable, V any](fn func(key K, value V, reason EvictReason)) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.onEvict = fn
+	}
+}
+
+// WithNegativeTTL makes GetOrLoad of caches with TTL remember loader errors
+// as negative entries for ttl, so that the loader is not called again for
+// the key until the entry expires. Context errors are never remembered.
+func WithNegativeTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.negativeTTL = ttl
+	}
+}
+
+// WithLoader registers the loader that refreshes values in the background,
+// see WithStaleTTL and WithRefreshAhead.
+func WithLoader[K comparable, V any](loader Loader[K, V]) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.loader = loader
+	}
+}
+
+// WithStaleTTL keeps values for ttl after their own TTL has passed. Get returns
+// such stale values and, if a loader is registered, refreshes them in the
+// background.
+func WithStaleTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.staleTTL = ttl
+	}
+}
+
+// WithRefreshAhead makes Get refresh values that become stale within d, so
+// that frequently read keys are reloaded before they go stale.
+func WithRefreshAhead[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.refreshAhead = d
+	}
+}
+
+// WithRefreshConcurrency limits the number of background refreshes running at
+// the same time, 4 by default. Refreshes over the limit are skipped.
+func WithRefreshConcurrency[K comparable, V any](n int) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.refreshConcurrency = n
+	}
+}
+
+// WithRefreshContext sets the context passed to the loader on background
+// refreshes. Once it is done no new refreshes are started and results of
+// running ones are discarded.
+func WithRefreshContext[K comparable, V any](ctx context.Context) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.refreshCtx = ctx
+	}
+}
+
+// WithSlidingExpiration makes the TTL passed to AddWithTTL an idle timeout:
+// every Get of the element moves its expiration forward by the TTL.
+func WithSlidingExpiration[K comparable, V any]() Option[K, V] {
+	return func(o *options[K, V]) {
+		o.sliding = true
+	}
+}
+
+// WithMaxLifetime limits how long reads can keep an element with sliding
+// expiration in the cache, counting from the moment it was added.
+func WithMaxLifetime[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.maxLifetime = d
+	}
+}
+
+// WithShards sets the number of shards of a ShardedCache and makes NewCache
+// create one. If n is 0, it is the smallest power of two that is at least
+// four times GOMAXPROCS.
+func WithShards[K comparable, V any](n int) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.sharded = true
+		o.shards = n
+	}
+}
+
+// WithHash sets the function that maps keys to shards of a ShardedCache and
+// hashes keys for the TinyLFU admission filter.
+func WithHash[K comparable, V any](hash func(key K) uint64) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.hash = hash
+	}
+}
+
+// WithEvictionPolicy sets the policy that picks the element to evict when the
+// cache is full. newPolicy is called with the capacity of the cache, for
+// example NewLFUPolicy[string, int]. LRU is used by default.
+func WithEvictionPolicy[K comparable, V any](newPolicy func(cap int) EvictionPolicy[K, V]) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.newPolicy = newPolicy
+	}
+}
+
+// WithTinyLFU puts the TinyLFU admission filter in front of the eviction
+// policy: when the cache is full, a new key is only added if it was accessed
+// recently more often than the element it would evict. Rejected keys are
+// counted in Stats().Rejections.
+func WithTinyLFU[K comparable, V any]() Option[K, V] {
+	return func(o *options[K, V]) {
+		o.tinyLFU = true
+	}
+}
+
+// WithMaxCost limits the total cost of the elements in addition to their
+// number. When a value doesn't fit, elements are evicted until it does.
+func WithMaxCost[K comparable, V any](maxCost int64) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.maxCost = maxCost
+	}
+}
+
+// WithSizer sets the function that computes the cost of values added without
+// an explicit cost. Without it every value costs 1.
+func WithSizer[K comparable, V any](sizer Sizer[K, V]) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.sizer = sizer
+	}
+}
+
+// WithTimingWheel keeps track of expirations in a hierarchical timing wheel
+// instead of a binary heap. Adding and removing elements with TTL takes O(1)
+// instead of O(log n) then, but elements expire up to about 1 ms late.
+func WithTimingWheel[K comparable, V any]() Option[K, V] {
+	return func(o *options[K, V]) {
+		o.timingWheel = true
+	}
+}
+
+// WithClock sets the clock that expirations are measured by, such as a
+// FakeClock in tests. The default one is the system clock.
+func WithClock[K comparable, V any](clock Clock) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.clock = clock
+	}
+}
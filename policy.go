@@ -0,0 +1,121 @@
+package lrucache
+
+// EvictionPolicy decides which element is evicted when the cache is full.
+// The cache calls its methods with the write lock held, except for Access of
+// the built-in policies that only mark the element, such as CLOCK.
+type EvictionPolicy[K comparable, V any] interface {
+	// Add starts tracking a new element.
+	Add(elem *Element[K, V])
+	// Access is called when the element is read or its value is replaced.
+	// Reads are reported in batches, so the order is approximate.
+	Access(elem *Element[K, V])
+	// Remove stops tracking the element, reason tells why it left the cache.
+	Remove(elem *Element[K, V], reason EvictReason)
+	// Victim returns the element to evict to make room for the key, or nil
+	// if there are no elements.
+	Victim(key K) *Element[K, V]
+	// Len returns the number of tracked elements.
+	Len() int
+	// Clear stops tracking all elements.
+	Clear()
+}
+
+// concurrentAccessPolicy is implemented by policies whose Access only
+// updates the refs of the element atomically. Reads call it right away with
+// the read lock instead of recording it in the read buffers.
+type concurrentAccessPolicy interface {
+	concurrentAccess()
+}
+
+// elementList is an intrusive doubly linked list of elements that the
+// eviction policies are built of. An element is in at most one list.
+type elementList[K comparable, V any] struct {
+	root Element[K, V] // sentinel, root.next is the front and root.prev is the back
+	len  int
+}
+
+func (l *elementList[K, V]) lazyInit() {
+	if l.root.next == nil {
+		l.root.next = &l.root
+		l.root.prev = &l.root
+	}
+}
+
+func (l *elementList[K, V]) front() *Element[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+func (l *elementList[K, V]) back() *Element[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+func (l *elementList[K, V]) pushFront(elem *Element[K, V]) {
+	l.lazyInit()
+
+	elem.prev = &l.root
+	elem.next = l.root.next
+	l.root.next.prev = elem
+	l.root.next = elem
+	elem.list = l
+	l.len++
+}
+
+func (l *elementList[K, V]) remove(elem *Element[K, V]) {
+	elem.prev.next = elem.next
+	elem.next.prev = elem.prev
+	elem.prev, elem.next, elem.list = nil, nil, nil
+	l.len--
+}
+
+func (l *elementList[K, V]) moveToFront(elem *Element[K, V]) {
+	if l.root.next == elem {
+		return
+	}
+	l.remove(elem)
+	l.pushFront(elem)
+}
+
+func (l *elementList[K, V]) clear() {
+	l.root.next, l.root.prev = nil, nil
+	l.len = 0
+}
+
+// lruPolicy evicts the least recently used element.
+type lruPolicy[K comparable, V any] struct {
+	list elementList[K, V]
+}
+
+// NewLRUPolicy returns the least recently used policy, the default one.
+func NewLRUPolicy[K comparable, V any](cap int) EvictionPolicy[K, V] {
+	return &lruPolicy[K, V]{}
+}
+
+func (p *lruPolicy[K, V]) Add(elem *Element[K, V]) {
+	p.list.pushFront(elem)
+}
+
+func (p *lruPolicy[K, V]) Access(elem *Element[K, V]) {
+	p.list.moveToFront(elem)
+}
+
+func (p *lruPolicy[K, V]) Remove(elem *Element[K, V], _ EvictReason) {
+	p.list.remove(elem)
+}
+
+func (p *lruPolicy[K, V]) Victim(K) *Element[K, V] {
+	return p.list.back()
+}
+
+func (p *lruPolicy[K, V]) Len() int {
+	return p.list.len
+}
+
+func (p *lruPolicy[K, V]) Clear() {
+	p.list.clear()
+}
@@ -0,0 +1,69 @@
+package lrucache
+
+// twoQueuePolicy is the full version of 2Q. New elements go to a FIFO queue,
+// and only keys that are added again soon after being evicted from it get to
+// the main LRU queue, which makes it resistant to scans.
+type twoQueuePolicy[K comparable, V any] struct {
+	in    elementList[K, V] // A1in, elements seen once
+	main  elementList[K, V] // Am, elements seen again
+	out   ghostList[K]      // A1out, keys evicted from in
+	inCap int
+}
+
+// NewTwoQueuePolicy returns the 2Q policy. A quarter of the capacity is
+// reserved for new elements and keys of half the capacity are remembered
+// after eviction.
+func NewTwoQueuePolicy[K comparable, V any](cap int) EvictionPolicy[K, V] {
+	return &twoQueuePolicy[K, V]{
+		out:   newGhostList[K](cap / 2),
+		inCap: twoQueueInCap(cap),
+	}
+}
+
+func twoQueueInCap(cap int) int {
+	if cap < 4 {
+		return 1
+	}
+	return cap / 4
+}
+
+func (p *twoQueuePolicy[K, V]) Add(elem *Element[K, V]) {
+	if p.out.remove(elem.key) {
+		p.main.pushFront(elem)
+		return
+	}
+	p.in.pushFront(elem)
+}
+
+func (p *twoQueuePolicy[K, V]) Access(elem *Element[K, V]) {
+	// accesses of new elements are often correlated, so they don't count
+	if elem.list == &p.main {
+		p.main.moveToFront(elem)
+	}
+}
+
+func (p *twoQueuePolicy[K, V]) Remove(elem *Element[K, V], reason EvictReason) {
+	fromIn := elem.list == &p.in
+
+	elem.list.remove(elem)
+	if fromIn && reason == EvictReasonCapacity {
+		p.out.add(elem.key)
+	}
+}
+
+func (p *twoQueuePolicy[K, V]) Victim(K) *Element[K, V] {
+	if p.in.len > 0 && (p.in.len >= p.inCap || p.main.len == 0) {
+		return p.in.back()
+	}
+	return p.main.back()
+}
+
+func (p *twoQueuePolicy[K, V]) Len() int {
+	return p.in.len + p.main.len
+}
+
+func (p *twoQueuePolicy[K, V]) Clear() {
+	p.in.clear()
+	p.main.clear()
+	p.out.clear()
+}
@@ -0,0 +1,111 @@
+package lrucache
+
+// arcPolicy is the Adaptive Replacement Cache. It keeps elements seen once
+// and elements seen at least twice in separate LRU lists, remembers the keys
+// evicted from each of them and moves the target size of the first list
+// towards the one whose evicted keys are added again.
+type arcPolicy[K comparable, V any] struct {
+	t1  elementList[K, V] // seen once
+	t2  elementList[K, V] // seen at least twice
+	b1  ghostList[K]      // evicted from t1
+	b2  ghostList[K]      // evicted from t2
+	p   int               // target size of t1
+	cap int
+}
+
+// NewARCPolicy returns the Adaptive Replacement Cache policy.
+func NewARCPolicy[K comparable, V any](cap int) EvictionPolicy[K, V] {
+	return &arcPolicy[K, V]{
+		b1:  newGhostList[K](cap),
+		b2:  newGhostList[K](cap),
+		cap: cap,
+	}
+}
+
+func (p *arcPolicy[K, V]) Add(elem *Element[K, V]) {
+	switch {
+	case p.b1.contains(elem.key):
+		p.p = p.target(elem.key)
+		p.b1.remove(elem.key)
+		p.t2.pushFront(elem)
+	case p.b2.contains(elem.key):
+		p.p = p.target(elem.key)
+		p.b2.remove(elem.key)
+		p.t2.pushFront(elem)
+	default:
+		p.t1.pushFront(elem)
+	}
+}
+
+func (p *arcPolicy[K, V]) Access(elem *Element[K, V]) {
+	if elem.list == &p.t2 {
+		p.t2.moveToFront(elem)
+		return
+	}
+	p.t1.remove(elem)
+	p.t2.pushFront(elem)
+}
+
+func (p *arcPolicy[K, V]) Remove(elem *Element[K, V], reason EvictReason) {
+	fromT1 := elem.list == &p.t1
+
+	elem.list.remove(elem)
+	if reason != EvictReasonCapacity {
+		return
+	}
+	if fromT1 {
+		p.b1.add(elem.key)
+	} else {
+		p.b2.add(elem.key)
+	}
+}
+
+func (p *arcPolicy[K, V]) Victim(key K) *Element[K, V] {
+	target := p.target(key)
+
+	if p.t1.len > 0 && (p.t1.len > target || (p.t1.len == target && p.b2.contains(key))) {
+		return p.t1.back()
+	}
+	if victim := p.t2.back(); victim != nil {
+		return victim
+	}
+	return p.t1.back()
+}
+
+// target returns the target size of t1 after the key is added.
+func (p *arcPolicy[K, V]) target(key K) int {
+	switch {
+	case p.b1.contains(key):
+		delta := 1
+		if p.b1.len() < p.b2.len() {
+			delta = p.b2.len() / p.b1.len()
+		}
+		if p.p+delta > p.cap {
+			return p.cap
+		}
+		return p.p + delta
+	case p.b2.contains(key):
+		delta := 1
+		if p.b2.len() < p.b1.len() {
+			delta = p.b1.len() / p.b2.len()
+		}
+		if p.p-delta < 0 {
+			return 0
+		}
+		return p.p - delta
+	default:
+		return p.p
+	}
+}
+
+func (p *arcPolicy[K, V]) Len() int {
+	return p.t1.len + p.t2.len
+}
+
+func (p *arcPolicy[K, V]) Clear() {
+	p.t1.clear()
+	p.t2.clear()
+	p.b1.clear()
+	p.b2.clear()
+	p.p = 0
+}
@@ -0,0 +1,48 @@
+package lrucache
+
+// clockPolicy is CLOCK, an approximation of LRU. Elements are kept in
+// insertion order and a read only sets their reference bit. The hand takes
+// elements from the back and gives those with the bit set a second chance
+// by clearing it and moving them to the front.
+type clockPolicy[K comparable, V any] struct {
+	list elementList[K, V]
+}
+
+// NewCLOCKPolicy returns the CLOCK policy. Reads don't take the write lock
+// of the cache with it.
+func NewCLOCKPolicy[K comparable, V any](cap int) EvictionPolicy[K, V] {
+	return &clockPolicy[K, V]{}
+}
+
+func (p *clockPolicy[K, V]) Add(elem *Element[K, V]) {
+	elem.refs.Store(0)
+	p.list.pushFront(elem)
+}
+
+func (p *clockPolicy[K, V]) Access(elem *Element[K, V]) {
+	elem.refs.Store(1)
+}
+
+func (p *clockPolicy[K, V]) concurrentAccess() {}
+
+func (p *clockPolicy[K, V]) Remove(elem *Element[K, V], _ EvictReason) {
+	p.list.remove(elem)
+}
+
+func (p *clockPolicy[K, V]) Victim(K) *Element[K, V] {
+	for {
+		elem := p.list.back()
+		if elem == nil || elem.refs.Swap(0) == 0 {
+			return elem
+		}
+		p.list.moveToFront(elem)
+	}
+}
+
+func (p *clockPolicy[K, V]) Len() int {
+	return p.list.len
+}
+
+func (p *clockPolicy[K, V]) Clear() {
+	p.list.clear()
+}
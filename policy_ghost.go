@@ -0,0 +1,54 @@
+package lrucache
+
+import "container/list"
+
+// ghostList remembers keys of recently evicted elements, up to cap keys.
+type ghostList[K comparable] struct {
+	cap   int
+	order *list.List // of K, most recent at the front
+	keys  map[K]*list.Element
+}
+
+func newGhostList[K comparable](cap int) ghostList[K] {
+	return ghostList[K]{
+		cap:   cap,
+		order: list.New(),
+		keys:  make(map[K]*list.Element),
+	}
+}
+
+func (g *ghostList[K]) add(key K) {
+	if g.cap <= 0 {
+		return
+	}
+	if g.order.Len() >= g.cap {
+		oldest := g.order.Back()
+		g.order.Remove(oldest)
+		delete(g.keys, oldest.Value.(K))
+	}
+	g.keys[key] = g.order.PushFront(key)
+}
+
+func (g *ghostList[K]) contains(key K) bool {
+	_, ok := g.keys[key]
+	return ok
+}
+
+// remove forgets the key and reports whether it was remembered.
+func (g *ghostList[K]) remove(key K) bool {
+	elem, ok := g.keys[key]
+	if ok {
+		g.order.Remove(elem)
+		delete(g.keys, key)
+	}
+	return ok
+}
+
+func (g *ghostList[K]) len() int {
+	return g.order.Len()
+}
+
+func (g *ghostList[K]) clear() {
+	g.order.Init()
+	g.keys = make(map[K]*list.Element)
+}
@@ -0,0 +1,102 @@
+package lrucache
+
+// lfuBucket holds the elements accessed freq times, least recently used at
+// the back.
+type lfuBucket[K comparable, V any] struct {
+	freq       uint64
+	items      elementList[K, V]
+	prev, next *lfuBucket[K, V]
+}
+
+// lfuPolicy evicts the least frequently used element, and the least recently
+// used one among elements with the same frequency. Buckets are kept in a list
+// ordered by frequency, so every operation takes constant time.
+type lfuPolicy[K comparable, V any] struct {
+	head    *lfuBucket[K, V] // bucket with the lowest frequency
+	buckets map[uint64]*lfuBucket[K, V]
+	len     int
+}
+
+// NewLFUPolicy returns the least frequently used policy.
+func NewLFUPolicy[K comparable, V any](cap int) EvictionPolicy[K, V] {
+	return &lfuPolicy[K, V]{
+		buckets: make(map[uint64]*lfuBucket[K, V]),
+	}
+}
+
+func (p *lfuPolicy[K, V]) Add(elem *Element[K, V]) {
+	elem.freq = 1
+
+	b := p.buckets[1]
+	if b == nil {
+		b = &lfuBucket[K, V]{freq: 1, next: p.head}
+		if p.head != nil {
+			p.head.prev = b
+		}
+		p.head = b
+		p.buckets[1] = b
+	}
+
+	b.items.pushFront(elem)
+	p.len++
+}
+
+func (p *lfuPolicy[K, V]) Access(elem *Element[K, V]) {
+	b := p.buckets[elem.freq]
+	elem.freq++
+
+	next := b.next
+	if next == nil || next.freq != elem.freq {
+		next = &lfuBucket[K, V]{freq: elem.freq, prev: b, next: b.next}
+		if b.next != nil {
+			b.next.prev = next
+		}
+		b.next = next
+		p.buckets[elem.freq] = next
+	}
+
+	b.items.remove(elem)
+	next.items.pushFront(elem)
+	if b.items.len == 0 {
+		p.removeBucket(b)
+	}
+}
+
+func (p *lfuPolicy[K, V]) Remove(elem *Element[K, V], _ EvictReason) {
+	b := p.buckets[elem.freq]
+
+	b.items.remove(elem)
+	if b.items.len == 0 {
+		p.removeBucket(b)
+	}
+	p.len--
+}
+
+func (p *lfuPolicy[K, V]) Victim(K) *Element[K, V] {
+	if p.head == nil {
+		return nil
+	}
+	return p.head.items.back()
+}
+
+func (p *lfuPolicy[K, V]) Len() int {
+	return p.len
+}
+
+func (p *lfuPolicy[K, V]) Clear() {
+	p.head = nil
+	p.buckets = make(map[uint64]*lfuBucket[K, V])
+	p.len = 0
+}
+
+func (p *lfuPolicy[K, V]) removeBucket(b *lfuBucket[K, V]) {
+	if b.prev != nil {
+		b.prev.next = b.next
+	} else {
+		p.head = b.next
+	}
+	if b.next != nil {
+		b.next.prev = b.prev
+	}
+	delete(p.buckets, b.freq)
+}
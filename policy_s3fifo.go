@@ -0,0 +1,101 @@
+package lrucache
+
+const (
+	s3fifoSmallShare = 10 // percent of the capacity used by the small queue
+	s3fifoMaxFreq    = 3
+)
+
+// s3fifoPolicy is S3-FIFO. New elements go to the small FIFO queue, and on
+// eviction those read there move to the main FIFO queue while the rest are
+// evicted and remembered in the ghost queue. Keys found in the ghost queue
+// go straight to the main queue. The main queue reinserts elements that were
+// read, each time decreasing their read count. Reads only update the count,
+// so they don't take the write lock of the cache.
+type s3fifoPolicy[K comparable, V any] struct {
+	small    elementList[K, V]
+	main     elementList[K, V]
+	ghost    ghostList[K]
+	smallCap int
+}
+
+// NewS3FIFOPolicy returns the S3-FIFO policy. A tenth of the capacity is
+// used by the small queue and as many keys as the capacity are remembered
+// after eviction.
+func NewS3FIFOPolicy[K comparable, V any](cap int) EvictionPolicy[K, V] {
+	return &s3fifoPolicy[K, V]{
+		ghost:    newGhostList[K](cap),
+		smallCap: s3fifoSmallCap(cap),
+	}
+}
+
+func s3fifoSmallCap(cap int) int {
+	if smallCap := cap * s3fifoSmallShare / 100; smallCap > 1 {
+		return smallCap
+	}
+	return 1
+}
+
+func (p *s3fifoPolicy[K, V]) Add(elem *Element[K, V]) {
+	elem.refs.Store(0)
+	if p.ghost.remove(elem.key) {
+		p.main.pushFront(elem)
+		return
+	}
+	p.small.pushFront(elem)
+}
+
+func (p *s3fifoPolicy[K, V]) Access(elem *Element[K, V]) {
+	for {
+		refs := elem.refs.Load()
+		if refs >= s3fifoMaxFreq || elem.refs.CompareAndSwap(refs, refs+1) {
+			return
+		}
+	}
+}
+
+func (p *s3fifoPolicy[K, V]) concurrentAccess() {}
+
+func (p *s3fifoPolicy[K, V]) Remove(elem *Element[K, V], reason EvictReason) {
+	fromSmall := elem.list == &p.small
+
+	elem.list.remove(elem)
+	if fromSmall && reason == EvictReasonCapacity {
+		p.ghost.add(elem.key)
+	}
+}
+
+func (p *s3fifoPolicy[K, V]) Victim(K) *Element[K, V] {
+	for {
+		if p.small.len > 0 && (p.small.len >= p.smallCap || p.main.len == 0) {
+			elem := p.small.back()
+			if elem.refs.Load() == 0 {
+				return elem
+			}
+			elem.refs.Store(0)
+			p.small.remove(elem)
+			p.main.pushFront(elem)
+			continue
+		}
+
+		elem := p.main.back()
+		if elem == nil {
+			return nil
+		}
+		refs := elem.refs.Load()
+		if refs == 0 {
+			return elem
+		}
+		elem.refs.Store(refs - 1)
+		p.main.moveToFront(elem)
+	}
+}
+
+func (p *s3fifoPolicy[K, V]) Len() int {
+	return p.small.len + p.main.len
+}
+
+func (p *s3fifoPolicy[K, V]) Clear() {
+	p.small.clear()
+	p.main.clear()
+	p.ghost.clear()
+}
@@ -0,0 +1,62 @@
+package lrucache
+
+// slruProtectedShare is the share of the capacity taken by the protected
+// segment of the SLRU policy, in percent.
+const slruProtectedShare = 80
+
+// slruPolicy is the segmented LRU. New elements go to the probation segment
+// and move to the protected one when they are accessed again, so a scan of
+// keys that are read once only displaces other elements on probation.
+type slruPolicy[K comparable, V any] struct {
+	probation    elementList[K, V]
+	protected    elementList[K, V]
+	protectedCap int
+}
+
+// NewSLRUPolicy returns the segmented LRU policy.
+func NewSLRUPolicy[K comparable, V any](cap int) EvictionPolicy[K, V] {
+	return &slruPolicy[K, V]{
+		protectedCap: cap * slruProtectedShare / 100,
+	}
+}
+
+func (p *slruPolicy[K, V]) Add(elem *Element[K, V]) {
+	p.probation.pushFront(elem)
+}
+
+func (p *slruPolicy[K, V]) Access(elem *Element[K, V]) {
+	if elem.list == &p.protected {
+		p.protected.moveToFront(elem)
+		return
+	}
+
+	p.probation.remove(elem)
+	p.protected.pushFront(elem)
+
+	// the least recently used protected element gets another chance on probation
+	if p.protected.len > p.protectedCap {
+		demoted := p.protected.back()
+		p.protected.remove(demoted)
+		p.probation.pushFront(demoted)
+	}
+}
+
+func (p *slruPolicy[K, V]) Remove(elem *Element[K, V], _ EvictReason) {
+	elem.list.remove(elem)
+}
+
+func (p *slruPolicy[K, V]) Victim(K) *Element[K, V] {
+	if victim := p.probation.back(); victim != nil {
+		return victim
+	}
+	return p.protected.back()
+}
+
+func (p *slruPolicy[K, V]) Len() int {
+	return p.probation.len + p.protected.len
+}
+
+func (p *slruPolicy[K, V]) Clear() {
+	p.probation.clear()
+	p.protected.clear()
+}
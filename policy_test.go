@@ -0,0 +1,257 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type policyFactory struct {
+	name string
+	new  func(cap int) EvictionPolicy[string, int]
+}
+
+func policyFactories() []policyFactory {
+	return []policyFactory{
+		{"LRU", NewLRUPolicy[string, int]},
+		{"LFU", NewLFUPolicy[string, int]},
+		{"SLRU", NewSLRUPolicy[string, int]},
+		{"2Q", NewTwoQueuePolicy[string, int]},
+		{"ARC", NewARCPolicy[string, int]},
+		{"CLOCK", NewCLOCKPolicy[string, int]},
+		{"S3-FIFO", NewS3FIFOPolicy[string, int]},
+	}
+}
+
+// hotHitRatio runs a workload where a small set of hot keys is read between
+// scans of keys that are never read again, and returns the hit ratio of the
+// hot keys.
+func hotHitRatio(cache *CacheWithTTL2[string, int]) float64 {
+	var hits, total int
+	scan := 0
+
+	for round := 0; round < 50; round++ {
+		for rep := 0; rep < 2; rep++ {
+			for i := 0; i < 20; i++ {
+				key := "hot" + strconv.Itoa(i)
+				total++
+				if _, ok := cache.Get(key); ok {
+					hits++
+				} else {
+					cache.Add(key, i)
+				}
+			}
+		}
+		for i := 0; i < 90; i++ {
+			scan++
+			cache.Add("scan"+strconv.Itoa(scan), scan)
+		}
+	}
+
+	return float64(hits) / float64(total)
+}
+
+func Test_EvictionPolicy(t *testing.T) {
+	for _, f := range policyFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			cache := NewWithTTL2[string, int](50, WithEvictionPolicy(f.new))
+			rnd := rand.New(rand.NewSource(1))
+
+			for i := 0; i < 20000; i++ {
+				key := strconv.Itoa(rnd.Intn(200))
+				switch rnd.Intn(10) {
+				case 0:
+					cache.Remove(key)
+				case 1:
+					cache.AddWithTTL(key, i, time.Duration(rnd.Intn(100))*time.Microsecond)
+				case 2, 3, 4:
+					cache.Add(key, i)
+				default:
+					cache.Get(key)
+				}
+
+				require.LessOrEqual(t, cache.Len(), 50)
+			}
+
+			cache.mutex.Lock()
+			cache.drainReads()
+			assert.Equal(t, len(cache.data), cache.queue.Len())
+			for cache.queue.Len() > 0 {
+				victim := cache.queue.Victim("")
+				require.NotNil(t, victim)
+				require.Same(t, victim, cache.data[victim.key])
+				cache.removeElement(victim, EvictReasonCapacity)
+			}
+			assert.Nil(t, cache.queue.Victim(""))
+			cache.mutex.Unlock()
+
+			cache.Add("key", 1)
+			cache.Clear()
+			assert.Equal(t, 0, cache.queue.Len())
+			cache.Add("key", 1)
+			assert.Equal(t, 1, cache.queue.Len())
+		})
+	}
+}
+
+func Test_EvictionPolicy_ScanResistance(t *testing.T) {
+	ratios := map[string]float64{}
+	for _, f := range policyFactories() {
+		ratios[f.name] = hotHitRatio(NewWithTTL2[string, int](100, WithEvictionPolicy(f.new)))
+	}
+
+	for _, name := range []string{"LFU", "SLRU", "2Q", "ARC", "S3-FIFO"} {
+		assert.Greater(t, ratios[name], ratios["LRU"]+0.2, name)
+	}
+}
+
+func Test_EvictionPolicy_TTL(t *testing.T) {
+	for _, f := range policyFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := NewWithTTL2[string, int](3, WithEvictionPolicy(f.new), WithClock[string, int](clock))
+
+			cache.AddWithTTL("first", 1, time.Millisecond*20)
+			cache.Add("second", 2)
+			cache.Get("second")
+
+			clock.Advance(time.Millisecond * 30)
+			_, ok := cache.Get("first")
+			assert.False(t, ok)
+			assert.Equal(t, 1, cache.queue.Len())
+
+			cache.Add("third", 3)
+			cache.Add("forth", 4)
+			cache.Add("fifth", 5)
+			assert.Equal(t, 3, cache.Len())
+			assert.Equal(t, 3, cache.queue.Len())
+		})
+	}
+}
+
+func Test_LFUPolicy(t *testing.T) {
+	cache := New[string, int](3, WithEvictionPolicy(NewLFUPolicy[string, int]))
+
+	cache.Add("first", 1)
+	cache.Add("second", 2)
+	cache.Add("third", 3)
+	for i := 0; i < 3; i++ {
+		cache.Get("first")
+		cache.Get("third")
+	}
+	cache.Get("second")
+
+	cache.Add("forth", 4)
+	_, ok := cache.Get("second")
+	assert.False(t, ok)
+
+	// new elements are evicted first among elements accessed as often
+	cache.Add("fifth", 5)
+	_, ok = cache.Get("forth")
+	assert.False(t, ok)
+	_, ok = cache.Get("first")
+	assert.True(t, ok)
+}
+
+func Test_ARCPolicy_Adapts(t *testing.T) {
+	p := NewARCPolicy[string, int](2).(*arcPolicy[string, int])
+	cache := New[string, int](2, WithEvictionPolicy(func(int) EvictionPolicy[string, int] { return p }))
+
+	cache.Add("first", 1)
+	cache.Add("second", 2)
+	cache.Add("third", 3) // first is evicted to b1
+	assert.True(t, p.b1.contains("first"))
+
+	// adding a key evicted from t1 again grows the target size of t1
+	cache.Add("first", 1)
+	assert.Equal(t, 1, p.p)
+	assert.Equal(t, 1, p.t2.len)
+}
+
+func Test_CLOCKPolicy(t *testing.T) {
+	cache := New[string, int](3, WithEvictionPolicy(NewCLOCKPolicy[string, int]))
+
+	cache.Add("first", 1)
+	cache.Add("second", 2)
+	cache.Add("third", 3)
+	cache.Get("first")
+
+	// reads are not buffered
+	for i := range cache.reads.stripes {
+		assert.Empty(t, cache.reads.stripes[i].elems)
+	}
+
+	// first gets a second chance, second is evicted
+	cache.Add("forth", 4)
+	_, ok := cache.Get("second")
+	assert.False(t, ok)
+	_, ok = cache.Get("first")
+	assert.True(t, ok)
+
+	// the chance is used up, so third and then first are evicted next
+	cache.Add("fifth", 5)
+	cache.Add("sixth", 6)
+	_, ok = cache.Get("third")
+	assert.False(t, ok)
+	_, ok = cache.Get("first")
+	assert.True(t, ok)
+}
+
+func Test_S3FIFOPolicy(t *testing.T) {
+	p := NewS3FIFOPolicy[string, int](10).(*s3fifoPolicy[string, int])
+	cache := New[string, int](10, WithEvictionPolicy(func(int) EvictionPolicy[string, int] { return p }))
+
+	for i := 0; i < 10; i++ {
+		cache.Add(strconv.Itoa(i), i)
+	}
+	cache.Get("0")
+
+	// 0 was read in the small queue, so it moves to main and 1 is evicted
+	cache.Add("10", 10)
+	assert.Equal(t, 1, p.main.len)
+	assert.True(t, p.ghost.contains("1"))
+	_, ok := cache.Get("0")
+	assert.True(t, ok)
+
+	// keys added again after eviction go to main
+	cache.Add("1", 1)
+	assert.Equal(t, 2, p.main.len)
+	assert.False(t, p.ghost.contains("1"))
+}
+
+func Test_ConcurrentAccessPolicy(t *testing.T) {
+	factories := []policyFactory{
+		{"CLOCK", NewCLOCKPolicy[string, int]},
+		{"S3-FIFO", NewS3FIFOPolicy[string, int]},
+	}
+	for _, f := range factories {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			cache := NewWithTTL2[string, int](20, WithEvictionPolicy(f.new))
+
+			var wg sync.WaitGroup
+			for g := 0; g < 4; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 2000; i++ {
+						key := strconv.Itoa((i * (g + 1)) % 40)
+						if _, ok := cache.Get(key); !ok {
+							cache.Add(key, i)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+
+			assert.Equal(t, 20, cache.Len())
+			assert.Equal(t, 20, cache.queue.Len())
+		})
+	}
+}
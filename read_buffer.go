@@ -0,0 +1,69 @@
+package lrucache
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// readBatchSize is the number of reads a stripe collects before they are
+// applied to the queue.
+const readBatchSize = 32
+
+// readBuffer collects elements read by Get so that they are reported to the
+// eviction policy in batches, under one write lock per batch instead of one
+// per read. Reads are spread over stripes with their own locks.
+type readBuffer[K comparable, V any] struct {
+	stripes []readStripe[K, V]
+}
+
+type readStripe[K comparable, V any] struct {
+	mutex sync.Mutex
+	elems []*Element[K, V]
+	_     [32]byte // keeps stripes on separate cache lines
+}
+
+func newReadBuffer[K comparable, V any]() readBuffer[K, V] {
+	n := 1
+	for n < runtime.GOMAXPROCS(0)*2 {
+		n <<= 1
+	}
+
+	return readBuffer[K, V]{stripes: make([]readStripe[K, V], n)}
+}
+
+// record adds the element to a random stripe. It returns the stripe once it
+// is full, the caller must drain it with the write lock of the cache held.
+func (b *readBuffer[K, V]) record(elem *Element[K, V]) *readStripe[K, V] {
+	s := &b.stripes[rand.Uint32()&uint32(len(b.stripes)-1)]
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.elems = append(s.elems, elem)
+	if len(s.elems) < readBatchSize {
+		return nil
+	}
+	return s
+}
+
+// drain calls access for all recorded elements.
+// Must be called with the write lock of the cache held.
+func (b *readBuffer[K, V]) drain(access func(elem *Element[K, V])) {
+	for i := range b.stripes {
+		b.stripes[i].drain(access)
+	}
+}
+
+// drain calls access for the recorded elements in the order they were read.
+// Must be called with the write lock of the cache held.
+func (s *readStripe[K, V]) drain(access func(elem *Element[K, V])) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, elem := range s.elems {
+		access(elem)
+		s.elems[i] = nil
+	}
+	s.elems = s.elems[:0]
+}
@@ -0,0 +1,73 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func Test_ReadBuffer(t *testing.T) {
+	cache := New[string, int](10)
+	for i := 0; i < 5; i++ {
+		cache.Add(strconv.Itoa(i), i)
+	}
+
+	keys := func() []string {
+		var keys []string
+		list := &cache.queue.(*lruPolicy[string, int]).list
+		for elem := list.front(); elem != &list.root; elem = elem.next {
+			keys = append(keys, elem.key)
+		}
+		return keys
+	}
+
+	cache.Get("1")
+	cache.Get("3")
+	cache.Remove("2")
+
+	// reads in different stripes are applied in no particular order
+	cache.mutex.Lock()
+	cache.drainReads()
+	cache.mutex.Unlock()
+	assert.ElementsMatch(t, []string{"3", "1"}, keys()[:2])
+	assert.Equal(t, []string{"4", "0"}, keys()[2:])
+
+	// a full stripe is applied by the read that filled it
+	stripes := len(cache.reads.stripes)
+	for i := 0; i < stripes*readBatchSize; i++ {
+		cache.Get("0")
+	}
+	assert.Equal(t, "0", keys()[0])
+}
+
+func Test_ReadBuffer_Concurrent(t *testing.T) {
+	cache := NewWithTTL2[int, int](100)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := (i * (g + 1)) % 200
+				switch i % 4 {
+				case 0:
+					cache.Add(key, i)
+				case 1:
+					cache.Remove(key)
+				default:
+					cache.Get(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	cache.mutex.Lock()
+	cache.drainReads()
+	cache.mutex.Unlock()
+
+	assert.LessOrEqual(t, cache.Len(), 100)
+	assert.Equal(t, cache.Len(), cache.queue.Len())
+}
@@ -0,0 +1,117 @@
+package lrucache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultRefreshConcurrency = 4
+
+// refresher reloads stale values in the background with the loader
+// registered by WithLoader.
+type refresher[K comparable, V any] struct {
+	loader Loader[K, V]
+	ctx    context.Context
+	ahead  time.Duration
+	slots  chan struct{} // limits the number of concurrent refreshes
+
+	mutex    sync.Mutex
+	inFlight map[K]struct{}
+}
+
+func newRefresher[K comparable, V any](o options[K, V]) *refresher[K, V] {
+	ctx := o.refreshCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	concurrency := o.refreshConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultRefreshConcurrency
+	}
+
+	return &refresher[K, V]{
+		loader:   o.loader,
+		ctx:      ctx,
+		ahead:    o.refreshAhead,
+		slots:    make(chan struct{}, concurrency),
+		inFlight: make(map[K]struct{}),
+	}
+}
+
+// due reports whether a value that becomes stale at staleAt should be
+// refreshed at now.
+func (r *refresher[K, V]) due(now, staleAt time.Time) bool {
+	return !now.Before(staleAt.Add(-r.ahead))
+}
+
+// start reloads the key in a new goroutine and stores the result with store.
+// It does nothing if the key is already being refreshed, if all refresh slots
+// are busy or if the context is done; a later Get will try again.
+func (r *refresher[K, V]) start(key K, store func(value V, ttl time.Duration)) {
+	if r.ctx.Err() != nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.inFlight[key]; ok {
+		return
+	}
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		return
+	}
+	r.inFlight[key] = struct{}{}
+
+	go func() {
+		defer func() {
+			r.mutex.Lock()
+			delete(r.inFlight, key)
+			r.mutex.Unlock()
+
+			<-r.slots
+		}()
+
+		// on error the stale value is served until it expires
+		value, ttl, err := r.loader(r.ctx, key)
+		if err == nil && r.ctx.Err() == nil {
+			store(value, ttl)
+		}
+	}()
+}
+
+// addLoaded adds a loaded value with ttl, or with the default TTL if ttl is
+// not positive and not NoExpiration.
+func (c *Cache[K, V]) addLoaded(key K, value V, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.unlock()
+
+	c.putLoaded(key, value, ttl)
+}
+
+// storeRefreshed stores the refreshed value of elem, unless elem was removed
+// or replaced by a new element of the key while the value was loaded.
+func (c *Cache[K, V]) storeRefreshed(elem *Element[K, V], value V, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.unlock()
+
+	if c.data[elem.key] == elem {
+		c.putLoaded(elem.key, value, ttl)
+	}
+}
+
+// putLoaded must be called with the write lock held, see addLoaded.
+func (c *Cache[K, V]) putLoaded(key K, value V, ttl time.Duration) {
+	elem := c.add(key, value)
+	switch {
+	case elem == nil:
+	case ttl > 0 || ttl == NoExpiration:
+		c.setTTL(elem, ttl)
+	default:
+		c.setDefaultTTL(elem)
+	}
+}
@@ -0,0 +1,209 @@
+package lrucache
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_StaleWhileRevalidate(t *testing.T) {
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			var calls atomic.Int32
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 10,
+				WithClock[string, any](clock),
+				WithStaleTTL[string, any](time.Minute),
+				WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+					calls.Add(1)
+					return "new", time.Minute, nil
+				}),
+			)
+
+			cache.AddWithTTL("key", "old", time.Millisecond*20)
+
+			value, ok := cache.Get("key")
+			assert.True(t, ok)
+			assert.Equal(t, "old", value)
+			assert.Equal(t, int32(0), calls.Load())
+
+			advance(t, clock, time.Millisecond*30)
+
+			// the stale value is served while it is refreshed
+			value, ok = cache.Get("key")
+			assert.True(t, ok)
+			assert.Equal(t, "old", value)
+
+			require.Eventually(t, func() bool {
+				value, _ := cache.Get("key")
+				return value == "new"
+			}, time.Second, time.Millisecond*5)
+			assert.Equal(t, int32(1), calls.Load())
+
+			// the new value is stale after its TTL and expires after the stale TTL
+			ttl, ok := cache.TTL("key")
+			assert.True(t, ok)
+			assert.Equal(t, time.Minute*2, ttl)
+		})
+
+		t.Run(f.name+"/hard TTL", func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 10, WithStaleTTL[string, any](time.Millisecond*30), WithClock[string, any](clock))
+
+			cache.AddWithTTL("key", "old", time.Millisecond*20)
+			advance(t, clock, time.Millisecond*25)
+
+			value, ok := cache.Get("key")
+			assert.True(t, ok)
+			assert.Equal(t, "old", value)
+
+			advance(t, clock, time.Millisecond*30)
+			_, ok = cache.Get("key")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func Test_RefreshAhead(t *testing.T) {
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			var calls atomic.Int32
+			cache := f.new(t, 10,
+				WithRefreshAhead[string, any](time.Minute),
+				WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+					calls.Add(1)
+					return "new", time.Hour, nil
+				}),
+			)
+
+			cache.AddWithTTL("cold", "old", time.Hour)
+			cache.AddWithTTL("hot", "old", time.Second*30)
+
+			cache.Get("cold")
+			value, _ := cache.Get("hot")
+			assert.Equal(t, "old", value)
+
+			require.Eventually(t, func() bool {
+				value, _ := cache.Get("hot")
+				return value == "new"
+			}, time.Second, time.Millisecond*5)
+
+			value, _ = cache.Get("cold")
+			assert.Equal(t, "old", value)
+			assert.Equal(t, int32(1), calls.Load())
+		})
+	}
+}
+
+func Test_Refresh_Removed(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	cache := NewWithTTL2[string, any](10,
+		WithRefreshAhead[string, any](time.Hour),
+		WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+			close(started)
+			<-release
+			return "new", time.Hour * 2, nil
+		}),
+	)
+
+	cache.AddWithTTL("key", "old", time.Minute)
+	cache.Get("key")
+	<-started
+	cache.Remove("key")
+	close(release)
+
+	require.Eventually(t, func() bool {
+		cache.refresher.mutex.Lock()
+		defer cache.refresher.mutex.Unlock()
+		return len(cache.refresher.inFlight) == 0
+	}, time.Second, time.Millisecond)
+
+	// the refresh doesn't bring the removed key back
+	_, ok := cache.Get("key")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func Test_Refresh_Concurrency(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	release := make(chan struct{})
+
+	cache := NewWithTTL2[string, any](10,
+		WithRefreshAhead[string, any](time.Hour),
+		WithRefreshConcurrency[string, any](2),
+		WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				max := maxRunning.Load()
+				if n <= max || maxRunning.CompareAndSwap(max, n) {
+					break
+				}
+			}
+			<-release
+			return "new", time.Hour * 2, nil
+		}),
+	)
+
+	for i := 0; i < 5; i++ {
+		cache.AddWithTTL(strconv.Itoa(i), "old", time.Minute)
+	}
+	for i := 0; i < 5; i++ {
+		cache.Get(strconv.Itoa(i))
+	}
+
+	require.Eventually(t, func() bool { return running.Load() == 2 }, time.Second, time.Millisecond)
+	close(release)
+	require.Eventually(t, func() bool { return running.Load() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), maxRunning.Load())
+
+	// skipped refreshes are started by later reads
+	for i := 0; i < 5; i++ {
+		require.Eventually(t, func() bool {
+			value, _ := cache.Get(strconv.Itoa(i))
+			return value == "new"
+		}, time.Second, time.Millisecond*5)
+	}
+}
+
+func Test_Refresh_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+
+	cache := NewWithTTL2[string, any](10,
+		WithRefreshAhead[string, any](time.Hour),
+		WithRefreshContext[string, any](ctx),
+		WithLoader(func(ctx context.Context, key string) (any, time.Duration, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, 0, ctx.Err()
+		}),
+	)
+
+	cache.AddWithTTL("key", "old", time.Minute)
+	cache.Get("key")
+	<-started
+	cancel()
+
+	require.Eventually(t, func() bool {
+		cache.refresher.mutex.Lock()
+		defer cache.refresher.mutex.Unlock()
+		return len(cache.refresher.inFlight) == 0
+	}, time.Second, time.Millisecond)
+
+	// no refreshes are started after the context is done
+	value, ok := cache.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "old", value)
+	cache.refresher.mutex.Lock()
+	assert.Empty(t, cache.refresher.inFlight)
+	cache.refresher.mutex.Unlock()
+}
@@ -0,0 +1,105 @@
+package lrucache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCap is returned for a capacity that is not positive.
+var ErrInvalidCap = errors.New("lrucache: capacity must be positive")
+
+// resizablePolicy is implemented by policies whose state depends on the
+// capacity. Resize calls it with the write lock held before evicting the
+// elements over the new capacity.
+type resizablePolicy interface {
+	resize(cap int)
+}
+
+// Resize changes the capacity of the cache and resizes the TinyLFU admission
+// filter with it. When it shrinks, expired elements are removed first and
+// then the elements chosen by the policy are evicted with EvictReasonCapacity
+// until the rest fit.
+func (c *Cache[K, V]) Resize(newCap int) error {
+	if newCap <= 0 {
+		return fmt.Errorf("%w, got %d", ErrInvalidCap, newCap)
+	}
+
+	c.mutex.Lock()
+	defer c.unlock()
+
+	c.cap = newCap
+	if p, ok := c.queue.(resizablePolicy); ok {
+		p.resize(newCap)
+	}
+	if c.admission != nil {
+		c.admission.resize(newCap)
+	}
+
+	if c.overCost(0, 0) {
+		c.removeExpired(c.clock.Now())
+		c.drainReads()
+
+		var zero K
+		c.makeRoom(zero, 0, 0)
+	}
+	return nil
+}
+
+// Resize splits the new capacity between the shards like NewSharded does,
+// it must not be less than the number of shards.
+func (c *ShardedCache[K, V]) Resize(newCap int) error {
+	n := len(c.shards)
+	if newCap < n {
+		return fmt.Errorf("%w for each of %d shards, got %d", ErrInvalidCap, n, newCap)
+	}
+
+	for i, shard := range c.shards {
+		shardCap := newCap / n
+		if i < newCap%n {
+			shardCap++
+		}
+		if err := shard.Resize(shardCap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *slruPolicy[K, V]) resize(cap int) {
+	p.protectedCap = cap * slruProtectedShare / 100
+
+	for p.protected.len > p.protectedCap {
+		demoted := p.protected.back()
+		p.protected.remove(demoted)
+		p.probation.pushFront(demoted)
+	}
+}
+
+func (p *twoQueuePolicy[K, V]) resize(cap int) {
+	p.inCap = twoQueueInCap(cap)
+	p.out.resize(cap / 2)
+}
+
+func (p *arcPolicy[K, V]) resize(cap int) {
+	p.cap = cap
+	if p.p > cap {
+		p.p = cap
+	}
+	p.b1.resize(cap)
+	p.b2.resize(cap)
+}
+
+func (p *s3fifoPolicy[K, V]) resize(cap int) {
+	p.smallCap = s3fifoSmallCap(cap)
+	p.ghost.resize(cap)
+}
+
+// resize changes the number of remembered keys, forgetting the oldest ones.
+func (g *ghostList[K]) resize(cap int) {
+	g.cap = cap
+	for g.order.Len() > 0 && g.order.Len() > cap {
+		oldest := g.order.Back()
+		g.order.Remove(oldest)
+		delete(g.keys, oldest.Value.(K))
+	}
+}
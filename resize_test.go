@@ -0,0 +1,84 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_Resize(t *testing.T) {
+	var evicted []string
+	clock := NewFakeClock(time.Now())
+	cache := NewWithTTL2[string, int](5, WithClock[string, int](clock), WithOnEvict(func(key string, _ int, reason EvictReason) {
+		evicted = append(evicted, key+":"+reason.String())
+	}))
+
+	cache.AddWithTTL("first", 1, time.Millisecond)
+	cache.Add("second", 2)
+	cache.AddWithTTL("third", 3, time.Minute)
+	cache.Add("forth", 4)
+	cache.Add("fifth", 5)
+	cache.Get("second")
+	clock.Advance(time.Millisecond * 5)
+
+	// expired elements go first, then the least recently used ones
+	require.NoError(t, cache.Resize(2))
+	assert.Equal(t, 2, cache.Cap())
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, []string{"first:expired", "third:capacity", "forth:capacity"}, evicted)
+	assert.Equal(t, 0, cache.expQueue.Len())
+	assert.Equal(t, 2, cache.queue.Len())
+
+	// growing evicts nothing and makes room for more elements
+	require.NoError(t, cache.Resize(4))
+	cache.Add("sixth", 6)
+	cache.Add("seventh", 7)
+	assert.Equal(t, 4, cache.Len())
+
+	assert.ErrorIs(t, cache.Resize(0), ErrInvalidCap)
+	assert.Equal(t, 4, cache.Cap())
+}
+
+func Test_Resize_Policies(t *testing.T) {
+	for _, f := range policyFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			cache := New[string, int](100, WithEvictionPolicy(f.new))
+			for round := 0; round < 3; round++ {
+				for i := 0; i < 100; i++ {
+					cache.Add(strconv.Itoa(i+round*50), i)
+					cache.Get(strconv.Itoa(i))
+				}
+			}
+
+			require.NoError(t, cache.Resize(10))
+			assert.Equal(t, 10, cache.Len())
+			assert.Equal(t, 10, cache.queue.Len())
+
+			for i := 0; i < 100; i++ {
+				cache.Add(strconv.Itoa(i), i)
+				require.LessOrEqual(t, cache.Len(), 10)
+			}
+		})
+	}
+}
+
+func Test_ShardedCache_Resize(t *testing.T) {
+	cache := NewSharded[string, int](10, WithShards[string, int](4))
+
+	for i := 0; i < 10; i++ {
+		cache.Add(strconv.Itoa(i), i)
+	}
+
+	require.NoError(t, cache.Resize(6))
+	assert.Equal(t, 6, cache.Cap())
+	assert.LessOrEqual(t, cache.Len(), 6)
+	assert.Equal(t, []int{2, 2, 1, 1}, []int{
+		cache.shards[0].Cap(), cache.shards[1].Cap(), cache.shards[2].Cap(), cache.shards[3].Cap(),
+	})
+
+	assert.ErrorIs(t, cache.Resize(3), ErrInvalidCap)
+	assert.Equal(t, 6, cache.Cap())
+}
@@ -0,0 +1,390 @@
+package resp
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+)
+
+const serverVersion = "7.0.0"
+
+type command struct {
+	// arity is the exact number of arguments including the command name,
+	// or the minimum number if negative
+	arity int
+	fn    func(s *Server, c *client, args [][]byte)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"ping":     {arity: -1, fn: (*Server).ping},
+		"echo":     {arity: 2, fn: (*Server).echo},
+		"quit":     {arity: -1, fn: (*Server).quit},
+		"hello":    {arity: -1, fn: (*Server).hello},
+		"select":   {arity: 2, fn: (*Server).selectDB},
+		"client":   {arity: -2, fn: (*Server).clientCmd},
+		"command":  {arity: -1, fn: (*Server).commandCmd},
+		"get":      {arity: 2, fn: (*Server).get},
+		"set":      {arity: -3, fn: (*Server).set},
+		"del":      {arity: -2, fn: (*Server).del},
+		"exists":   {arity: -2, fn: (*Server).exists},
+		"ttl":      {arity: 2, fn: (*Server).ttl},
+		"pttl":     {arity: 2, fn: (*Server).pttl},
+		"flushdb":  {arity: -1, fn: (*Server).flush},
+		"flushall": {arity: -1, fn: (*Server).flush},
+		"dbsize":   {arity: 1, fn: (*Server).dbsize},
+		"info":     {arity: -1, fn: (*Server).info},
+	}
+}
+
+func (s *Server) exec(c *client, args [][]byte) {
+	name := strings.ToLower(string(args[0]))
+
+	cmd, ok := commands[name]
+	if !ok {
+		_ = c.w.WriteError(fmt.Sprintf("ERR unknown command '%s', with args beginning with: %s", args[0], quoteArgs(args[1:])))
+		return
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		_ = c.w.WriteError(fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+		return
+	}
+
+	cmd.fn(s, c, args)
+}
+
+func (s *Server) ping(c *client, args [][]byte) {
+	switch len(args) {
+	case 1:
+		_ = c.w.WriteSimpleString("PONG")
+	case 2:
+		_ = c.w.WriteBulk(args[1])
+	default:
+		_ = c.w.WriteError("ERR wrong number of arguments for 'ping' command")
+	}
+}
+
+func (s *Server) echo(c *client, args [][]byte) {
+	_ = c.w.WriteBulk(args[1])
+}
+
+func (s *Server) quit(c *client, args [][]byte) {
+	c.quit = true
+	_ = c.w.WriteSimpleString("OK")
+}
+
+// hello switches the protocol version and replies with the server properties.
+// HELLO [protover [AUTH username password] [SETNAME clientname]]
+func (s *Server) hello(c *client, args [][]byte) {
+	proto := c.w.Protocol()
+
+	if len(args) > 1 {
+		v, err := strconv.Atoi(string(args[1]))
+		if err != nil {
+			_ = c.w.WriteError("ERR Protocol version is not an integer or out of range")
+			return
+		}
+		if v != 2 && v != 3 {
+			_ = c.w.WriteError("NOPROTO unsupported protocol version")
+			return
+		}
+		proto = v
+
+		// there is no authentication, AUTH and SETNAME are validated and ignored
+		for i := 2; i < len(args); i++ {
+			switch strings.ToLower(string(args[i])) {
+			case "auth":
+				i += 2
+			case "setname":
+				i++
+			default:
+				_ = c.w.WriteError("ERR syntax error")
+				return
+			}
+			if i >= len(args) {
+				_ = c.w.WriteError("ERR syntax error")
+				return
+			}
+		}
+	}
+
+	c.w.SetProtocol(proto)
+
+	_ = c.w.WriteMapLen(7)
+	_ = c.w.WriteBulkString("server")
+	_ = c.w.WriteBulkString("redis")
+	_ = c.w.WriteBulkString("version")
+	_ = c.w.WriteBulkString(serverVersion)
+	_ = c.w.WriteBulkString("proto")
+	_ = c.w.WriteInteger(int64(proto))
+	_ = c.w.WriteBulkString("id")
+	_ = c.w.WriteInteger(c.id)
+	_ = c.w.WriteBulkString("mode")
+	_ = c.w.WriteBulkString("standalone")
+	_ = c.w.WriteBulkString("role")
+	_ = c.w.WriteBulkString("master")
+	_ = c.w.WriteBulkString("modules")
+	_ = c.w.WriteArrayLen(0)
+}
+
+func (s *Server) selectDB(c *client, args [][]byte) {
+	if string(args[1]) != "0" {
+		_ = c.w.WriteError("ERR DB index is out of range")
+		return
+	}
+	_ = c.w.WriteSimpleString("OK")
+}
+
+// clientCmd accepts the CLIENT subcommands that client libraries send on
+// connect, such as SETNAME and SETINFO.
+func (s *Server) clientCmd(c *client, args [][]byte) {
+	switch strings.ToLower(string(args[1])) {
+	case "id":
+		_ = c.w.WriteInteger(c.id)
+	case "getname":
+		_ = c.w.WriteNull()
+	default:
+		_ = c.w.WriteSimpleString("OK")
+	}
+}
+
+// commandCmd replies with an empty command table, which is enough for
+// redis-cli and client libraries that introspect commands on connect.
+func (s *Server) commandCmd(c *client, args [][]byte) {
+	if len(args) > 1 && strings.ToLower(string(args[1])) == "count" {
+		_ = c.w.WriteInteger(int64(len(commands)))
+		return
+	}
+	_ = c.w.WriteArrayLen(0)
+}
+
+func (s *Server) get(c *client, args [][]byte) {
+	value, ok := s.cache.Get(string(args[1]))
+	if !ok {
+		_ = c.w.WriteNull()
+		return
+	}
+	_ = c.w.WriteBulk(value)
+}
+
+// set stores a value. SET key value [NX | XX] [GET] [EX seconds | PX milliseconds | KEEPTTL]
+func (s *Server) set(c *client, args [][]byte) {
+	var (
+		key             = string(args[1])
+		value           = args[2]
+		ttl             time.Duration
+		nx, xx, get     bool
+		keepTTL, hasTTL bool
+	)
+
+	for i := 3; i < len(args); i++ {
+		switch opt := strings.ToLower(string(args[i])); opt {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "get":
+			get = true
+		case "keepttl":
+			keepTTL = true
+		case "ex", "px":
+			if hasTTL || i+1 == len(args) {
+				_ = c.w.WriteError("ERR syntax error")
+				return
+			}
+			i++
+
+			n, err := strconv.ParseInt(string(args[i]), 10, 64)
+			if err != nil {
+				_ = c.w.WriteError("ERR value is not an integer or out of range")
+				return
+			}
+
+			unit := time.Millisecond
+			if opt == "ex" {
+				unit = time.Second
+			}
+			// TTLs that don't fit in a time.Duration are rejected like
+			// expire times that overflow in redis
+			if n <= 0 || n > math.MaxInt64/int64(unit) {
+				_ = c.w.WriteError("ERR invalid expire time in 'set' command")
+				return
+			}
+
+			hasTTL = true
+			ttl = time.Duration(n) * unit
+		default:
+			_ = c.w.WriteError("ERR syntax error")
+			return
+		}
+	}
+	if (nx && xx) || (keepTTL && hasTTL) {
+		_ = c.w.WriteError("ERR syntax error")
+		return
+	}
+
+	if !nx && !xx && !get && !keepTTL {
+		if hasTTL {
+			s.cache.AddWithTTL(key, value, ttl)
+		} else {
+			s.cache.Add(key, value)
+		}
+		_ = c.w.WriteSimpleString("OK")
+		return
+	}
+
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	// only GET reads the old value, other options check the key with TTL,
+	// which counts no hit or miss and doesn't move the key in the queue
+	var (
+		old    []byte
+		exists bool
+	)
+	if get {
+		old, exists = s.cache.Get(key)
+	} else {
+		_, exists = s.cache.TTL(key)
+	}
+
+	if (nx && exists) || (xx && !exists) {
+		if get && exists {
+			_ = c.w.WriteBulk(old)
+		} else {
+			_ = c.w.WriteNull()
+		}
+		return
+	}
+
+	switch {
+	case keepTTL && exists && s.cache.Replace(key, value):
+		// the expiration is kept as it is, without the default TTL or jitter
+	case hasTTL:
+		s.cache.AddWithTTL(key, value, ttl)
+	default:
+		s.cache.Add(key, value)
+	}
+
+	switch {
+	case !get:
+		_ = c.w.WriteSimpleString("OK")
+	case exists:
+		_ = c.w.WriteBulk(old)
+	default:
+		_ = c.w.WriteNull()
+	}
+}
+
+func (s *Server) del(c *client, args [][]byte) {
+	var n int64
+	for _, arg := range args[1:] {
+		key := string(arg)
+		if _, ok := s.cache.TTL(key); ok {
+			s.cache.Remove(key)
+			n++
+		}
+	}
+	_ = c.w.WriteInteger(n)
+}
+
+func (s *Server) exists(c *client, args [][]byte) {
+	var n int64
+	for _, arg := range args[1:] {
+		if _, ok := s.cache.TTL(string(arg)); ok {
+			n++
+		}
+	}
+	_ = c.w.WriteInteger(n)
+}
+
+func (s *Server) ttl(c *client, args [][]byte) {
+	s.writeTTL(c, string(args[1]), time.Second)
+}
+
+func (s *Server) pttl(c *client, args [][]byte) {
+	s.writeTTL(c, string(args[1]), time.Millisecond)
+}
+
+// writeTTL replies with the time to live in units, -1 for keys without TTL
+// and -2 for missing keys.
+func (s *Server) writeTTL(c *client, key string, unit time.Duration) {
+	ttl, ok := s.cache.TTL(key)
+	switch {
+	case !ok:
+		_ = c.w.WriteInteger(-2)
+	case ttl == lrucache.NoExpiration:
+		_ = c.w.WriteInteger(-1)
+	default:
+		_ = c.w.WriteInteger(int64((ttl + unit/2) / unit))
+	}
+}
+
+func (s *Server) flush(c *client, args [][]byte) {
+	if len(args) > 2 {
+		_ = c.w.WriteError("ERR syntax error")
+		return
+	}
+	if len(args) == 2 {
+		switch strings.ToLower(string(args[1])) {
+		case "sync", "async":
+		default:
+			_ = c.w.WriteError("ERR syntax error")
+			return
+		}
+	}
+
+	s.cache.Clear()
+	_ = c.w.WriteSimpleString("OK")
+}
+
+func (s *Server) dbsize(c *client, args [][]byte) {
+	_ = c.w.WriteInteger(int64(s.cache.Len()))
+}
+
+func (s *Server) info(c *client, args [][]byte) {
+	sections := map[string]bool{}
+	for _, arg := range args[1:] {
+		sections[strings.ToLower(string(arg))] = true
+	}
+	all := len(sections) == 0 || sections["all"] || sections["default"] || sections["everything"]
+
+	var b strings.Builder
+	if all || sections["server"] {
+		fmt.Fprintf(&b, "# Server\r\n")
+		fmt.Fprintf(&b, "redis_version:%s\r\n", serverVersion)
+		fmt.Fprintf(&b, "redis_mode:standalone\r\n")
+		fmt.Fprintf(&b, "process_id:%d\r\n", os.Getpid())
+		fmt.Fprintf(&b, "uptime_in_seconds:%d\r\n", int64(time.Since(s.started).Seconds()))
+		fmt.Fprintf(&b, "\r\n")
+	}
+	if all || sections["cache"] {
+		fmt.Fprintf(&b, "# Cache\r\n")
+		fmt.Fprintf(&b, "cache_len:%d\r\n", s.cache.Len())
+		fmt.Fprintf(&b, "cache_cap:%d\r\n", s.cache.Cap())
+		fmt.Fprintf(&b, "\r\n")
+	}
+	if all || sections["keyspace"] {
+		fmt.Fprintf(&b, "# Keyspace\r\n")
+		if n := s.cache.Len(); n > 0 {
+			fmt.Fprintf(&b, "db0:keys=%d\r\n", n)
+		}
+	}
+
+	_ = c.w.WriteVerbatim("txt", b.String())
+}
+
+func quoteArgs(args [][]byte) string {
+	var b strings.Builder
+	for _, arg := range args {
+		fmt.Fprintf(&b, "'%s' ", arg)
+	}
+	return b.String()
+}
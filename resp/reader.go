@@ -0,0 +1,369 @@
+// Package resp implements the Redis serialization protocol (RESP2 and RESP3)
+// and a server that exposes a cache to Redis clients.
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	maxBulkLen  = 512 << 20
+	maxArrayLen = 1 << 20
+	maxInline   = 64 << 10
+	maxDepth    = 32
+
+	// bulkChunk is the initial buffer size of bulk strings, larger buffers
+	// grow as the data arrives
+	bulkChunk = 64 << 10
+)
+
+var ErrProtocol = errors.New("resp: protocol error")
+
+type Type byte
+
+const (
+	SimpleString   Type = '+'
+	Error          Type = '-'
+	Integer        Type = ':'
+	BulkString     Type = '$'
+	Array          Type = '*'
+	Null           Type = '_'
+	Boolean        Type = '#'
+	Double         Type = ','
+	BigNumber      Type = '('
+	BulkError      Type = '!'
+	VerbatimString Type = '='
+	Map            Type = '%'
+	Set            Type = '~'
+	Push           Type = '>'
+)
+
+// Value is a single decoded RESP value. Str holds the payload of string-like
+// types, Int the payload of integers, Bool of booleans and Elems the children
+// of aggregates. Maps are stored as a flat key, value, key, value... list.
+// Nil is set for the RESP2 null bulk string and null array.
+type Value struct {
+	Type  Type
+	Str   []byte
+	Int   int64
+	Bool  bool
+	Elems []Value
+	Nil   bool
+}
+
+type Reader struct {
+	rd *bufio.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{rd: bufio.NewReader(r)}
+}
+
+// Buffered reports the number of bytes that can be read without blocking.
+func (r *Reader) Buffered() int {
+	return r.rd.Buffered()
+}
+
+// ReadCommand reads a client command. Commands are either arrays of bulk
+// strings or inline commands separated by spaces.
+func (r *Reader) ReadCommand() ([][]byte, error) {
+	for {
+		prefix, err := r.rd.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+
+		if Type(prefix[0]) != Array {
+			args, err := r.readInline()
+			if err != nil {
+				return nil, err
+			}
+			// empty lines are ignored the same way redis does
+			if len(args) == 0 {
+				continue
+			}
+			return args, nil
+		}
+
+		args, err := r.readArray()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) == 0 {
+			continue
+		}
+		return args, nil
+	}
+}
+
+// readArray reads a command sent as an array of bulk strings. Other element
+// types, including nested aggregates, are rejected.
+func (r *Reader) readArray() ([][]byte, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	n, err := parseLen(line[1:], maxArrayLen)
+	if err != nil {
+		return nil, err
+	}
+
+	// the arguments are appended as they arrive, so that a large length
+	// alone doesn't allocate memory
+	var args [][]byte
+	for i := 0; i < n; i++ {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if len(line) == 0 || Type(line[0]) != BulkString {
+			return nil, fmt.Errorf("%w: expected bulk string, got %q", ErrProtocol, line)
+		}
+
+		size, err := parseLen(line[1:], maxBulkLen)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, fmt.Errorf("%w: null bulk string argument", ErrProtocol)
+		}
+
+		arg, err := r.readBulk(size)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// ReadValue reads a value of any type. Aggregates can be nested up to
+// maxDepth levels.
+func (r *Reader) ReadValue() (Value, error) {
+	return r.readValue(0)
+}
+
+func (r *Reader) readValue(depth int) (Value, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return Value{}, err
+	}
+	if len(line) == 0 {
+		return Value{}, fmt.Errorf("%w: empty line", ErrProtocol)
+	}
+
+	t, payload := Type(line[0]), line[1:]
+	switch t {
+	case SimpleString, Error, Double, BigNumber:
+		return Value{Type: t, Str: payload}, nil
+	case Integer:
+		n, err := parseInt(payload)
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{Type: t, Int: n}, nil
+	case Null:
+		if len(payload) != 0 {
+			return Value{}, fmt.Errorf("%w: invalid null", ErrProtocol)
+		}
+		return Value{Type: t, Nil: true}, nil
+	case Boolean:
+		switch string(payload) {
+		case "t":
+			return Value{Type: t, Bool: true}, nil
+		case "f":
+			return Value{Type: t, Bool: false}, nil
+		}
+		return Value{}, fmt.Errorf("%w: invalid boolean %q", ErrProtocol, payload)
+	case BulkString, BulkError, VerbatimString:
+		n, err := parseLen(payload, maxBulkLen)
+		if err != nil {
+			return Value{}, err
+		}
+		if n < 0 {
+			return Value{Type: t, Nil: true}, nil
+		}
+
+		str, err := r.readBulk(n)
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{Type: t, Str: str}, nil
+	case Array, Set, Push, Map:
+		if depth >= maxDepth {
+			return Value{}, fmt.Errorf("%w: too many nested aggregates", ErrProtocol)
+		}
+		n, err := parseLen(payload, maxArrayLen)
+		if err != nil {
+			return Value{}, err
+		}
+		if n < 0 {
+			return Value{Type: t, Nil: true}, nil
+		}
+		if t == Map {
+			n *= 2
+		}
+
+		// like the arguments of commands, the elements are appended as they
+		// arrive
+		elems := []Value{}
+		for i := 0; i < n; i++ {
+			elem, err := r.readValue(depth + 1)
+			if err != nil {
+				return Value{}, unexpectedEOF(err)
+			}
+			elems = append(elems, elem)
+		}
+		return Value{Type: t, Elems: elems}, nil
+	}
+
+	return Value{}, fmt.Errorf("%w: unknown type '%c'", ErrProtocol, t)
+}
+
+// readBulk reads a payload of n bytes terminated by CRLF. The buffer starts
+// at bulkChunk bytes and doubles as the payload arrives.
+func (r *Reader) readBulk(n int) ([]byte, error) {
+	size := n + 2
+	buf := make([]byte, 0, minInt(size, bulkChunk))
+	for len(buf) < size {
+		if len(buf) == cap(buf) {
+			grown := make([]byte, len(buf), minInt(2*cap(buf), size))
+			copy(grown, buf)
+			buf = grown
+		}
+		m, err := r.rd.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+m]
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+
+	if buf[n] != '\r' || buf[n+1] != '\n' {
+		return nil, fmt.Errorf("%w: bulk string is not terminated by CRLF", ErrProtocol)
+	}
+	return buf[:n], nil
+}
+
+func (r *Reader) readInline() ([][]byte, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	return splitInline(line)
+}
+
+// readLine reads a line terminated by CRLF and returns it without the
+// terminator. A bare LF is accepted for inline commands typed by hand.
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.rd.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		full := append([]byte(nil), line...)
+		for errors.Is(err, bufio.ErrBufferFull) && len(full) <= maxInline {
+			line, err = r.rd.ReadSlice('\n')
+			full = append(full, line...)
+		}
+		if len(full) > maxInline {
+			return nil, fmt.Errorf("%w: line is too long", ErrProtocol)
+		}
+		line = full
+	}
+	if err != nil {
+		if len(line) > 0 {
+			return nil, unexpectedEOF(err)
+		}
+		return nil, err
+	}
+
+	line = line[:len(line)-1]
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return line, nil
+}
+
+func splitInline(line []byte) ([][]byte, error) {
+	var args [][]byte
+
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ', '\t':
+			i++
+		case '"', '\'':
+			quote := line[i]
+			arg := []byte{}
+			i++
+			for ; i < len(line) && line[i] != quote; i++ {
+				if quote == '"' && line[i] == '\\' && i+1 < len(line) {
+					i++
+					arg = append(arg, unescape(line[i]))
+					continue
+				}
+				arg = append(arg, line[i])
+			}
+			if i == len(line) {
+				return nil, fmt.Errorf("%w: unbalanced quotes in request", ErrProtocol)
+			}
+			i++
+			args = append(args, arg)
+		default:
+			end := bytes.IndexAny(line[i:], " \t")
+			if end < 0 {
+				end = len(line) - i
+			}
+			args = append(args, append([]byte(nil), line[i:i+end]...))
+			i += end
+		}
+	}
+
+	return args, nil
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	}
+	return c
+}
+
+func parseInt(b []byte) (int64, error) {
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid integer %q", ErrProtocol, b)
+	}
+	return n, nil
+}
+
+func parseLen(b []byte, max int64) (int, error) {
+	n, err := parseInt(b)
+	if err != nil {
+		return 0, err
+	}
+	if n < -1 || n > max {
+		return 0, fmt.Errorf("%w: invalid length %d", ErrProtocol, n)
+	}
+	return int(n), nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
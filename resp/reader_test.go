@@ -0,0 +1,228 @@
+package resp
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Reader_ReadCommand(t *testing.T) {
+	cases := []struct {
+		name     string
+		stream   string
+		expected [][]string
+	}{
+		{
+			// captured from redis-cli 7.2
+			name:     "redis-cli set with expiration",
+			stream:   "*5\r\n$3\r\nSET\r\n$3\r\nkey\r\n$5\r\nvalue\r\n$2\r\nEX\r\n$2\r\n10\r\n",
+			expected: [][]string{{"SET", "key", "value", "EX", "10"}},
+		},
+		{
+			// captured from go-redis v9 connection handshake
+			name: "pipelined handshake",
+			stream: "*2\r\n$5\r\nhello\r\n$1\r\n3\r\n" +
+				"*4\r\n$6\r\nclient\r\n$7\r\nsetinfo\r\n$8\r\nLIB-NAME\r\n$8\r\ngo-redis\r\n" +
+				"*2\r\n$3\r\nget\r\n$3\r\nfoo\r\n",
+			expected: [][]string{
+				{"hello", "3"},
+				{"client", "setinfo", "LIB-NAME", "go-redis"},
+				{"get", "foo"},
+			},
+		},
+		{
+			name:     "binary safe bulk string",
+			stream:   "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$4\r\na\r\nb\r\n",
+			expected: [][]string{{"SET", "k", "a\r\nb"}},
+		},
+		{
+			name:     "empty bulk string",
+			stream:   "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$0\r\n\r\n",
+			expected: [][]string{{"SET", "k", ""}},
+		},
+		{
+			name:     "inline commands",
+			stream:   "PING\r\nset key \"hello world\"\nGET 'key'\r\n",
+			expected: [][]string{{"PING"}, {"set", "key", "hello world"}, {"GET", "key"}},
+		},
+		{
+			name:     "inline escapes",
+			stream:   "SET k \"a\\nb\\\"c\"\r\n",
+			expected: [][]string{{"SET", "k", "a\nb\"c"}},
+		},
+		{
+			name:     "empty lines and arrays are skipped",
+			stream:   "\r\n*0\r\n  \r\nPING\r\n",
+			expected: [][]string{{"PING"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(c.stream))
+
+			var commands [][]string
+			for {
+				args, err := r.ReadCommand()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+
+				command := make([]string, len(args))
+				for i, arg := range args {
+					command[i] = string(arg)
+				}
+				commands = append(commands, command)
+			}
+
+			assert.Equal(t, c.expected, commands)
+		})
+	}
+}
+
+func Test_Reader_ReadCommandErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		stream   string
+		expected error
+	}{
+		{name: "invalid array length", stream: "*x\r\n", expected: ErrProtocol},
+		{name: "negative bulk length", stream: "*1\r\n$-5\r\n", expected: ErrProtocol},
+		{name: "bulk string too long", stream: "*1\r\n$1000000000\r\n", expected: ErrProtocol},
+		{name: "bulk string without CRLF", stream: "*1\r\n$3\r\nGETxx", expected: ErrProtocol},
+		{name: "integer instead of bulk string", stream: "*1\r\n:1\r\n", expected: ErrProtocol},
+		{name: "null bulk string argument", stream: "*1\r\n$-1\r\n", expected: ErrProtocol},
+		{name: "nested array", stream: "*1\r\n*1\r\n$3\r\nGET\r\n", expected: ErrProtocol},
+		{name: "nested map", stream: "*1\r\n%1\r\n$1\r\nk\r\n$1\r\nv\r\n", expected: ErrProtocol},
+		{name: "unbalanced quotes", stream: "SET k \"value\r\n", expected: ErrProtocol},
+		{name: "truncated array", stream: "*2\r\n$3\r\nGET\r\n", expected: io.ErrUnexpectedEOF},
+		{name: "truncated bulk string", stream: "*1\r\n$3\r\nGE", expected: io.ErrUnexpectedEOF},
+		{name: "truncated line", stream: "*1\r\n$3", expected: io.ErrUnexpectedEOF},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(c.stream)).ReadCommand()
+			assert.ErrorIs(t, err, c.expected)
+		})
+	}
+}
+
+func Test_Reader_ReadValue(t *testing.T) {
+	cases := []struct {
+		name     string
+		stream   string
+		expected Value
+	}{
+		{name: "simple string", stream: "+OK\r\n", expected: Value{Type: SimpleString, Str: []byte("OK")}},
+		{name: "error", stream: "-ERR syntax error\r\n", expected: Value{Type: Error, Str: []byte("ERR syntax error")}},
+		{name: "integer", stream: ":-42\r\n", expected: Value{Type: Integer, Int: -42}},
+		{name: "bulk string", stream: "$5\r\nhello\r\n", expected: Value{Type: BulkString, Str: []byte("hello")}},
+		{name: "null bulk string", stream: "$-1\r\n", expected: Value{Type: BulkString, Nil: true}},
+		{name: "null array", stream: "*-1\r\n", expected: Value{Type: Array, Nil: true}},
+		{name: "null", stream: "_\r\n", expected: Value{Type: Null, Nil: true}},
+		{name: "boolean", stream: "#t\r\n", expected: Value{Type: Boolean, Bool: true}},
+		{name: "double", stream: ",3.14\r\n", expected: Value{Type: Double, Str: []byte("3.14")}},
+		{name: "big number", stream: "(3492890328409238509324850943850943825024385\r\n", expected: Value{Type: BigNumber, Str: []byte("3492890328409238509324850943850943825024385")}},
+		{name: "bulk error", stream: "!10\r\nERR failed\r\n", expected: Value{Type: BulkError, Str: []byte("ERR failed")}},
+		{name: "verbatim string", stream: "=8\r\ntxt:info\r\n", expected: Value{Type: VerbatimString, Str: []byte("txt:info")}},
+		{
+			name:   "nested array",
+			stream: "*2\r\n:1\r\n*1\r\n+two\r\n",
+			expected: Value{Type: Array, Elems: []Value{
+				{Type: Integer, Int: 1},
+				{Type: Array, Elems: []Value{{Type: SimpleString, Str: []byte("two")}}},
+			}},
+		},
+		{
+			// captured from the HELLO 3 reply of redis 7.2, trimmed
+			name:   "map",
+			stream: "%2\r\n$6\r\nserver\r\n$5\r\nredis\r\n$5\r\nproto\r\n:3\r\n",
+			expected: Value{Type: Map, Elems: []Value{
+				{Type: BulkString, Str: []byte("server")},
+				{Type: BulkString, Str: []byte("redis")},
+				{Type: BulkString, Str: []byte("proto")},
+				{Type: Integer, Int: 3},
+			}},
+		},
+		{
+			name:   "set",
+			stream: "~1\r\n+a\r\n",
+			expected: Value{Type: Set, Elems: []Value{
+				{Type: SimpleString, Str: []byte("a")},
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			value, err := NewReader(strings.NewReader(c.stream)).ReadValue()
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, value)
+		})
+	}
+}
+
+func Test_Reader_ReadValueErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		stream string
+	}{
+		{name: "unknown type", stream: "?1\r\n"},
+		{name: "invalid integer", stream: ":one\r\n"},
+		{name: "invalid boolean", stream: "#x\r\n"},
+		{name: "invalid null", stream: "_x\r\n"},
+		{name: "empty line", stream: "\r\n"},
+		{name: "line too long", stream: "+" + strings.Repeat("a", maxInline+1) + "\r\n"},
+		{name: "too deeply nested", stream: strings.Repeat("*1\r\n", maxDepth+1) + ":1\r\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(c.stream)).ReadValue()
+			assert.ErrorIs(t, err, ErrProtocol)
+		})
+	}
+}
+
+// Test_Reader_HostileHeaders checks that lengths sent by a client don't
+// allocate memory before the data arrives.
+func Test_Reader_HostileHeaders(t *testing.T) {
+	readCommand := func(r *Reader) error {
+		_, err := r.ReadCommand()
+		return err
+	}
+	readValue := func(r *Reader) error {
+		_, err := r.ReadValue()
+		return err
+	}
+
+	nested := strings.Repeat("*1048576\r\n", 20)
+	cases := []struct {
+		name   string
+		stream string
+		read   func(r *Reader) error
+	}{
+		{name: "nested arrays command", stream: nested, read: readCommand},
+		{name: "nested arrays value", stream: nested, read: readValue},
+		{name: "large bulk string command", stream: "*1048576\r\n$536870912\r\nabc", read: readCommand},
+		{name: "large bulk string value", stream: "$536870912\r\nabc", read: readValue},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			err := c.read(NewReader(strings.NewReader(c.stream)))
+			runtime.ReadMemStats(&after)
+
+			assert.Error(t, err)
+			assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package resp
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/bemmanue/LRUCacheService/internal/tcpserver"
+)
+
+var ErrServerClosed = tcpserver.ErrServerClosed
+
+// Server serves a cache to Redis clients. Every connection starts with RESP2
+// and may switch to RESP3 with the HELLO command.
+type Server struct {
+	cache   lrucache.ICacheWithTTL[string, []byte]
+	started time.Time
+	tcp     tcpserver.Server
+
+	// writeMutex makes conditional SET (NX, XX, GET, KEEPTTL) atomic with
+	// respect to each other, plain SET doesn't take it
+	writeMutex sync.Mutex
+
+	lastClientID atomic.Int64
+}
+
+type client struct {
+	id   int64
+	w    *Writer
+	quit bool
+}
+
+func NewServer(cache lrucache.ICacheWithTTL[string, []byte]) *Server {
+	s := &Server{
+		cache:   cache,
+		started: time.Now(),
+	}
+	s.tcp.Handler = s.serveConn
+
+	return s
+}
+
+// Serve accepts connections on l until Close is called, in which case it
+// returns ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	return s.tcp.Serve(l)
+}
+
+// Close stops all listeners, closes open connections and waits for their
+// goroutines to finish.
+func (s *Server) Close() error {
+	return s.tcp.Close()
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	r := NewReader(conn)
+	c := &client{
+		id: s.lastClientID.Add(1),
+		w:  NewWriter(conn),
+	}
+
+	for !c.quit {
+		args, err := r.ReadCommand()
+		if err != nil {
+			if errors.Is(err, ErrProtocol) {
+				_ = c.w.WriteError("ERR Protocol error: " + strings.TrimPrefix(err.Error(), ErrProtocol.Error()+": "))
+				_ = c.w.Flush()
+			}
+			return
+		}
+
+		s.exec(c, args)
+
+		// pipelined commands are answered in a single write
+		if r.Buffered() == 0 || c.quit {
+			if err := c.w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package resp
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	lrucache "github.com/bemmanue/LRUCacheService"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *Reader
+}
+
+func newTestServer(t *testing.T, cap int, opts ...lrucache.Option[string, []byte]) (*lrucache.CacheWithTTL2[string, []byte], string) {
+	cache := lrucache.NewWithTTL2[string, []byte](cap, opts...)
+	srv := NewServer(cache)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(l)
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, srv.Close())
+		assert.ErrorIs(t, <-done, ErrServerClosed)
+	})
+
+	return cache, l.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *testConn {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	return &testConn{t: t, conn: conn, r: NewReader(conn)}
+}
+
+// do sends a command as an array of bulk strings and reads one reply.
+func (c *testConn) do(args ...string) Value {
+	c.send(args...)
+	return c.read()
+}
+
+func (c *testConn) send(args ...string) {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+
+	_, err := c.conn.Write([]byte(b.String()))
+	require.NoError(c.t, err)
+}
+
+func (c *testConn) read() Value {
+	v, err := c.r.ReadValue()
+	require.NoError(c.t, err)
+	return v
+}
+
+func simple(s string) Value {
+	return Value{Type: SimpleString, Str: []byte(s)}
+}
+
+func bulk(s string) Value {
+	return Value{Type: BulkString, Str: []byte(s)}
+}
+
+func integer(n int64) Value {
+	return Value{Type: Integer, Int: n}
+}
+
+func errorValue(s string) Value {
+	return Value{Type: Error, Str: []byte(s)}
+}
+
+var nullBulk = Value{Type: BulkString, Nil: true}
+
+func Test_Server_GetSetDel(t *testing.T) {
+	cache, addr := newTestServer(t, 3)
+	c := dial(t, addr)
+
+	assert.Equal(t, simple("PONG"), c.do("PING"))
+	assert.Equal(t, bulk("hi"), c.do("PING", "hi"))
+	assert.Equal(t, nullBulk, c.do("GET", "key"))
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value"))
+	assert.Equal(t, bulk("value"), c.do("GET", "key"))
+	assert.Equal(t, integer(1), c.do("EXISTS", "key", "random key"))
+	assert.Equal(t, integer(1), c.do("DBSIZE"))
+	assert.Equal(t, integer(1), c.do("DEL", "key", "random key"))
+	assert.Equal(t, integer(0), c.do("EXISTS", "key"))
+	assert.Equal(t, 0, cache.Len())
+}
+
+func Test_Server_SetOptions(t *testing.T) {
+	_, addr := newTestServer(t, 3)
+	c := dial(t, addr)
+
+	assert.Equal(t, nullBulk, c.do("SET", "key", "value", "XX"))
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value", "NX"))
+	assert.Equal(t, nullBulk, c.do("SET", "key", "another value", "NX"))
+	assert.Equal(t, bulk("value"), c.do("SET", "key", "another value", "XX", "GET"))
+	assert.Equal(t, bulk("another value"), c.do("GET", "key"))
+
+	assert.Equal(t, errorValue("ERR syntax error"), c.do("SET", "key", "value", "NX", "XX"))
+	assert.Equal(t, errorValue("ERR syntax error"), c.do("SET", "key", "value", "EX"))
+	assert.Equal(t, errorValue("ERR syntax error"), c.do("SET", "key", "value", "EX", "1", "PX", "1"))
+	assert.Equal(t, errorValue("ERR syntax error"), c.do("SET", "key", "value", "SOMETIMES"))
+	assert.Equal(t, errorValue("ERR invalid expire time in 'set' command"), c.do("SET", "key", "value", "EX", "0"))
+	assert.Equal(t, errorValue("ERR invalid expire time in 'set' command"), c.do("SET", "key", "value", "EX", "9223372037"))
+	assert.Equal(t, errorValue("ERR invalid expire time in 'set' command"), c.do("SET", "key", "value", "PX", "9223372036855"))
+	assert.Equal(t, errorValue("ERR value is not an integer or out of range"), c.do("SET", "key", "value", "PX", "soon"))
+}
+
+func Test_Server_TTL(t *testing.T) {
+	_, addr := newTestServer(t, 3)
+	c := dial(t, addr)
+
+	assert.Equal(t, integer(-2), c.do("TTL", "key"))
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value"))
+	assert.Equal(t, integer(-1), c.do("TTL", "key"))
+	assert.Equal(t, integer(-1), c.do("PTTL", "key"))
+
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value", "EX", "100"))
+	assert.Equal(t, integer(100), c.do("TTL", "key"))
+
+	pttl := c.do("PTTL", "key")
+	assert.InDelta(t, 100000, pttl.Int, 1000)
+
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "another value", "KEEPTTL"))
+	assert.Equal(t, integer(100), c.do("TTL", "key"))
+
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value"))
+	assert.Equal(t, integer(-1), c.do("TTL", "key"))
+
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value", "PX", "20"))
+	require.Eventually(t, func() bool {
+		return c.do("GET", "key").Nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, integer(-2), c.do("PTTL", "key"))
+}
+
+func Test_Server_DefaultTTL(t *testing.T) {
+	cache, addr := newTestServer(t, 3, lrucache.WithDefaultTTL[string, []byte](time.Minute))
+	c := dial(t, addr)
+
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value"))
+	assert.Equal(t, integer(60), c.do("TTL", "key"))
+
+	// KEEPTTL keeps keys without expiration as they are
+	cache.AddWithTTL("key", []byte("value"), lrucache.NoExpiration)
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "another value", "KEEPTTL"))
+	assert.Equal(t, integer(-1), c.do("TTL", "key"))
+}
+
+func Test_Server_FlushAndInfo(t *testing.T) {
+	cache, addr := newTestServer(t, 5)
+	c := dial(t, addr)
+
+	cache.Add("first", []byte("1"))
+	cache.Add("second", []byte("2"))
+
+	info := c.do("INFO")
+	assert.Equal(t, BulkString, info.Type)
+	assert.Contains(t, string(info.Str), "# Server\r\nredis_version:")
+	assert.Contains(t, string(info.Str), "cache_len:2\r\ncache_cap:5\r\n")
+	assert.Contains(t, string(info.Str), "db0:keys=2\r\n")
+
+	info = c.do("INFO", "keyspace")
+	assert.Equal(t, "# Keyspace\r\ndb0:keys=2\r\n", string(info.Str))
+
+	assert.Equal(t, simple("OK"), c.do("FLUSHDB"))
+	assert.Equal(t, integer(0), c.do("DBSIZE"))
+	assert.Equal(t, errorValue("ERR syntax error"), c.do("FLUSHDB", "LATER"))
+}
+
+func Test_Server_Hello(t *testing.T) {
+	_, addr := newTestServer(t, 3)
+	c := dial(t, addr)
+
+	assert.Equal(t, errorValue("NOPROTO unsupported protocol version"), c.do("HELLO", "4"))
+
+	reply := c.do("HELLO", "3", "SETNAME", "test")
+	require.Equal(t, Map, reply.Type)
+	assert.Equal(t, []Value{bulk("proto"), integer(3)}, reply.Elems[4:6])
+
+	assert.Equal(t, Value{Type: Null, Nil: true}, c.do("GET", "key"))
+	assert.Equal(t, VerbatimString, c.do("INFO").Type)
+
+	reply = c.do("HELLO", "2")
+	assert.Equal(t, Array, reply.Type)
+	assert.Equal(t, nullBulk, c.do("GET", "key"))
+}
+
+func Test_Server_Errors(t *testing.T) {
+	_, addr := newTestServer(t, 3)
+	c := dial(t, addr)
+
+	assert.Equal(t, errorValue("ERR unknown command 'FOO', with args beginning with: 'bar' "), c.do("FOO", "bar"))
+	assert.Equal(t, errorValue("ERR wrong number of arguments for 'get' command"), c.do("GET"))
+	assert.Equal(t, errorValue("ERR DB index is out of range"), c.do("SELECT", "1"))
+	assert.Equal(t, simple("OK"), c.do("SELECT", "0"))
+
+	_, err := c.conn.Write([]byte("*1\r\n$x\r\n"))
+	require.NoError(t, err)
+	reply := c.read()
+	assert.Equal(t, Error, reply.Type)
+	assert.True(t, strings.HasPrefix(string(reply.Str), "ERR Protocol error: "))
+}
+
+func Test_Server_PipelineAndInline(t *testing.T) {
+	_, addr := newTestServer(t, 3)
+	c := dial(t, addr)
+
+	_, err := c.conn.Write([]byte("SET key \"hello world\"\r\n*2\r\n$3\r\nGET\r\n$3\r\nkey\r\nQUIT\r\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, simple("OK"), c.read())
+	assert.Equal(t, bulk("hello world"), c.read())
+	assert.Equal(t, simple("OK"), c.read())
+
+	_, err = c.r.ReadValue()
+	assert.Error(t, err)
+}
+
+func Test_Server_KeepTTLJitter(t *testing.T) {
+	_, addr := newTestServer(t, 3,
+		lrucache.WithTTLJitter[string, []byte](0.5),
+		lrucache.WithClock[string, []byte](lrucache.NewFakeClock(time.Now())),
+	)
+	c := dial(t, addr)
+
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value", "PX", "100000"))
+	pttl := c.do("PTTL", "key")
+
+	// KEEPTTL doesn't jitter the TTL again
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, simple("OK"), c.do("SET", "key", "value", "KEEPTTL"))
+		assert.Equal(t, pttl, c.do("PTTL", "key"))
+	}
+}
+
+func Test_Server_SetStats(t *testing.T) {
+	cache, addr := newTestServer(t, 3)
+	c := dial(t, addr)
+
+	// only SET ... GET reads the value
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, simple("OK"), c.do("SET", "key", "value"))
+	}
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value", "XX"))
+	assert.Equal(t, nullBulk, c.do("SET", "other", "value", "XX"))
+	assert.Equal(t, simple("OK"), c.do("SET", "key", "value", "KEEPTTL"))
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(0), stats.Hits)
+	assert.Equal(t, uint64(0), stats.Misses)
+
+	assert.Equal(t, bulk("value"), c.do("SET", "key", "new", "GET"))
+	assert.Equal(t, uint64(1), cache.Stats().Hits)
+}
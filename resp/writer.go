@@ -0,0 +1,125 @@
+package resp
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// Writer encodes replies. Types that only exist in RESP3 are downgraded to
+// their RESP2 equivalents until the protocol is switched with SetProtocol.
+type Writer struct {
+	wr    *bufio.Writer
+	proto int
+	buf   []byte
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		wr:    bufio.NewWriter(w),
+		proto: 2,
+	}
+}
+
+func (w *Writer) Protocol() int {
+	return w.proto
+}
+
+func (w *Writer) SetProtocol(proto int) {
+	w.proto = proto
+}
+
+func (w *Writer) WriteSimpleString(s string) error {
+	return w.writeLine(SimpleString, s)
+}
+
+func (w *Writer) WriteError(msg string) error {
+	return w.writeLine(Error, msg)
+}
+
+func (w *Writer) WriteInteger(n int64) error {
+	w.buf = append(w.buf[:0], byte(Integer))
+	w.buf = strconv.AppendInt(w.buf, n, 10)
+	w.buf = append(w.buf, '\r', '\n')
+	_, err := w.wr.Write(w.buf)
+	return err
+}
+
+func (w *Writer) WriteBulk(b []byte) error {
+	if err := w.writeHeader(BulkString, len(b)); err != nil {
+		return err
+	}
+	if _, err := w.wr.Write(b); err != nil {
+		return err
+	}
+	_, err := w.wr.WriteString("\r\n")
+	return err
+}
+
+func (w *Writer) WriteBulkString(s string) error {
+	return w.WriteBulk([]byte(s))
+}
+
+// WriteVerbatim writes a RESP3 verbatim string with a three letter format
+// such as "txt", or a bulk string for RESP2 clients.
+func (w *Writer) WriteVerbatim(format, s string) error {
+	if w.proto < 3 {
+		return w.WriteBulkString(s)
+	}
+
+	if err := w.writeHeader(VerbatimString, len(format)+1+len(s)); err != nil {
+		return err
+	}
+	_, err := w.wr.WriteString(format + ":" + s + "\r\n")
+	return err
+}
+
+// WriteNull writes a null reply: "_" in RESP3 and the null bulk string in RESP2.
+func (w *Writer) WriteNull() error {
+	if w.proto < 3 {
+		_, err := w.wr.WriteString("$-1\r\n")
+		return err
+	}
+	_, err := w.wr.WriteString("_\r\n")
+	return err
+}
+
+func (w *Writer) WriteArrayLen(n int) error {
+	return w.writeHeader(Array, n)
+}
+
+// WriteMapLen starts a map of n key-value pairs. RESP2 clients receive an
+// array of 2*n elements.
+func (w *Writer) WriteMapLen(n int) error {
+	if w.proto < 3 {
+		return w.writeHeader(Array, n*2)
+	}
+	return w.writeHeader(Map, n)
+}
+
+func (w *Writer) Flush() error {
+	return w.wr.Flush()
+}
+
+func (w *Writer) writeHeader(t Type, n int) error {
+	w.buf = append(w.buf[:0], byte(t))
+	w.buf = strconv.AppendInt(w.buf, int64(n), 10)
+	w.buf = append(w.buf, '\r', '\n')
+	_, err := w.wr.Write(w.buf)
+	return err
+}
+
+func (w *Writer) writeLine(t Type, s string) error {
+	w.buf = append(w.buf[:0], byte(t))
+	for i := 0; i < len(s); i++ {
+		// simple strings and errors can't contain line breaks
+		if c := s[i]; c != '\r' && c != '\n' {
+			w.buf = append(w.buf, c)
+		} else {
+			w.buf = append(w.buf, ' ')
+		}
+	}
+	w.buf = append(w.buf, '\r', '\n')
+	_, err := w.wr.Write(w.buf)
+	return err
+}
@@ -0,0 +1,90 @@
+package resp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Writer(t *testing.T) {
+	cases := []struct {
+		name     string
+		write    func(w *Writer)
+		expected map[int]string
+	}{
+		{
+			name:     "simple string",
+			write:    func(w *Writer) { _ = w.WriteSimpleString("OK") },
+			expected: map[int]string{2: "+OK\r\n", 3: "+OK\r\n"},
+		},
+		{
+			name:     "simple string with line break",
+			write:    func(w *Writer) { _ = w.WriteSimpleString("a\r\nb") },
+			expected: map[int]string{2: "+a  b\r\n", 3: "+a  b\r\n"},
+		},
+		{
+			name:     "error",
+			write:    func(w *Writer) { _ = w.WriteError("ERR syntax error") },
+			expected: map[int]string{2: "-ERR syntax error\r\n", 3: "-ERR syntax error\r\n"},
+		},
+		{
+			name:     "integer",
+			write:    func(w *Writer) { _ = w.WriteInteger(-2) },
+			expected: map[int]string{2: ":-2\r\n", 3: ":-2\r\n"},
+		},
+		{
+			name:     "bulk string",
+			write:    func(w *Writer) { _ = w.WriteBulk([]byte("a\r\nb")) },
+			expected: map[int]string{2: "$4\r\na\r\nb\r\n", 3: "$4\r\na\r\nb\r\n"},
+		},
+		{
+			name:     "null",
+			write:    func(w *Writer) { _ = w.WriteNull() },
+			expected: map[int]string{2: "$-1\r\n", 3: "_\r\n"},
+		},
+		{
+			name:     "verbatim string",
+			write:    func(w *Writer) { _ = w.WriteVerbatim("txt", "info") },
+			expected: map[int]string{2: "$4\r\ninfo\r\n", 3: "=8\r\ntxt:info\r\n"},
+		},
+		{
+			name: "map",
+			write: func(w *Writer) {
+				_ = w.WriteMapLen(1)
+				_ = w.WriteBulkString("proto")
+				_ = w.WriteInteger(3)
+			},
+			expected: map[int]string{
+				2: "*2\r\n$5\r\nproto\r\n:3\r\n",
+				3: "%1\r\n$5\r\nproto\r\n:3\r\n",
+			},
+		},
+		{
+			name: "array",
+			write: func(w *Writer) {
+				_ = w.WriteArrayLen(2)
+				_ = w.WriteBulkString("a")
+				_ = w.WriteNull()
+			},
+			expected: map[int]string{
+				2: "*2\r\n$1\r\na\r\n$-1\r\n",
+				3: "*2\r\n$1\r\na\r\n_\r\n",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		for proto, expected := range c.expected {
+			var buf bytes.Buffer
+			w := NewWriter(&buf)
+			w.SetProtocol(proto)
+
+			c.write(w)
+			require.NoError(t, w.Flush())
+
+			assert.Equal(t, expected, buf.String(), "%s, RESP%d", c.name, proto)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package lrucache
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"runtime"
+	"time"
+)
+
+// ShardedCache spreads keys over independent CacheWithTTL2 shards so that
+// operations on different shards don't contend for the same lock. Every shard
+// evicts its own least recently used element, so the eviction order is only
+// approximately LRU for the cache as a whole.
+type ShardedCache[K comparable, V any] struct {
+	maxCost int64
+	shards  []*CacheWithTTL2[K, V]
+	hash    func(key K) uint64
+}
+
+// NewSharded creates a cache of cap elements split between shards. The number
+// of shards and the hash function are set with WithShards and WithHash.
+// The maximum cost set with WithMaxCost is split between shards as well, so
+// a value is rejected as too large if it exceeds the share of its shard. There
+// are never more shards than elements or units of cost. Like New, it panics
+// if cap is not positive.
+func NewSharded[K comparable, V any](cap int, opts ...Option[K, V]) *ShardedCache[K, V] {
+	var o options[K, V]
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	n := o.shards
+	if n <= 0 {
+		n = defaultShards()
+	}
+	if n > cap {
+		n = cap
+	}
+	// a shard with no share of the cost would be unlimited
+	if o.maxCost > 0 && int64(n) > o.maxCost {
+		n = int(o.maxCost)
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	hash := o.hash
+	if hash == nil {
+		hash = newDefaultHash[K]()
+	}
+
+	cache := &ShardedCache[K, V]{
+		maxCost: o.maxCost,
+		shards:  make([]*CacheWithTTL2[K, V], n),
+		hash:    hash,
+	}
+	for i := range cache.shards {
+		shardCap := cap / n
+		if i < cap%n {
+			shardCap++
+		}
+		shardOpts := opts
+		if o.maxCost > 0 {
+			shardMaxCost := o.maxCost / int64(n)
+			if int64(i) < o.maxCost%int64(n) {
+				shardMaxCost++
+			}
+			shardOpts = append(shardOpts[:len(shardOpts):len(shardOpts)], WithMaxCost[K, V](shardMaxCost))
+		}
+		if o.jitterSeeded {
+			// shards with the same seed would jitter the same way
+			shardOpts = append(shardOpts[:len(shardOpts):len(shardOpts)], WithJitterSeed[K, V](o.jitterSeed+int64(i)))
+		}
+		cache.shards[i] = NewWithTTL2(shardCap, shardOpts...)
+	}
+
+	// the refresh concurrency limit applies to the whole cache
+	for _, shard := range cache.shards[1:] {
+		shard.refresher = cache.shards[0].refresher
+	}
+
+	return cache
+}
+
+// defaultShards returns a power of two that is at least four times the number
+// of usable CPUs.
+func defaultShards() int {
+	n := 1
+	for n < runtime.GOMAXPROCS(0)*4 {
+		n <<= 1
+	}
+	return n
+}
+
+func (c *ShardedCache[K, V]) shard(key K) *CacheWithTTL2[K, V] {
+	return c.shards[c.hash(key)%uint64(len(c.shards))]
+}
+
+// Shards returns the number of shards.
+func (c *ShardedCache[K, V]) Shards() int {
+	return len(c.shards)
+}
+
+func (c *ShardedCache[K, V]) Cap() int {
+	var cap int
+	for _, shard := range c.shards {
+		cap += shard.Cap()
+	}
+	return cap
+}
+
+func (c *ShardedCache[K, V]) Len() int {
+	var n int
+	for _, shard := range c.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+// MaxCost returns the maximum total cost of the elements, or 0 if only their
+// number is limited.
+func (c *ShardedCache[K, V]) MaxCost() int64 {
+	return c.maxCost
+}
+
+// Cost returns the total cost of the elements.
+func (c *ShardedCache[K, V]) Cost() int64 {
+	var cost int64
+	for _, shard := range c.shards {
+		cost += shard.Cost()
+	}
+	return cost
+}
+
+// Clear clears the shards one by one, elements added to already cleared
+// shards while Clear runs are kept.
+func (c *ShardedCache[K, V]) Clear() {
+	for _, shard := range c.shards {
+		shard.Clear()
+	}
+}
+
+func (c *ShardedCache[K, V]) Close() {
+	for _, shard := range c.shards {
+		shard.Close()
+	}
+}
+
+func (c *ShardedCache[K, V]) Add(key K, value V) {
+	c.shard(key).Add(key, value)
+}
+
+func (c *ShardedCache[K, V]) Replace(key K, value V) bool {
+	return c.shard(key).Replace(key, value)
+}
+
+func (c *ShardedCache[K, V]) AddWithTTL(key K, value V, ttl time.Duration) {
+	c.shard(key).AddWithTTL(key, value, ttl)
+}
+
+// AddWithCost adds the value with the default TTL and the given cost, see
+// Cache.AddWithCost.
+func (c *ShardedCache[K, V]) AddWithCost(key K, value V, cost int64) error {
+	return c.shard(key).AddWithCost(key, value, cost)
+}
+
+// AddWithTTLAndCost adds the value with ttl and the given cost, see
+// Cache.AddWithCost.
+func (c *ShardedCache[K, V]) AddWithTTLAndCost(key K, value V, ttl time.Duration, cost int64) error {
+	return c.shard(key).AddWithTTLAndCost(key, value, ttl, cost)
+}
+
+func (c *ShardedCache[K, V]) AddWithSlidingTTL(key K, value V, idle, maxLifetime time.Duration) {
+	c.shard(key).AddWithSlidingTTL(key, value, idle, maxLifetime)
+}
+
+func (c *ShardedCache[K, V]) AddNegative(key K, err error, ttl time.Duration) {
+	c.shard(key).AddNegative(key, err, ttl)
+}
+
+func (c *ShardedCache[K, V]) Get(key K) (V, bool) {
+	return c.shard(key).Get(key)
+}
+
+func (c *ShardedCache[K, V]) Lookup(key K) (value V, ok bool, err error) {
+	return c.shard(key).Lookup(key)
+}
+
+func (c *ShardedCache[K, V]) GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (V, error) {
+	return c.shard(key).GetOrLoad(ctx, key, loader)
+}
+
+func (c *ShardedCache[K, V]) Remove(key K) {
+	c.shard(key).Remove(key)
+}
+
+// TTL returns the time left until the element expires, or NoExpiration if the
+// element was added without TTL. ok is false if there is no such element.
+func (c *ShardedCache[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	return c.shard(key).TTL(key)
+}
+
+// Stats returns the sum of the shard counters.
+func (c *ShardedCache[K, V]) Stats() Stats {
+	var stats Stats
+	for _, shard := range c.shards {
+		stats = stats.add(shard.Stats())
+	}
+	return stats
+}
+
+func (c *ShardedCache[K, V]) ResetStats() {
+	for _, shard := range c.shards {
+		shard.ResetStats()
+	}
+}
+
+// newDefaultHash returns a hash function for the key type. Keys of types
+// other than strings, integers and floats are hashed field by field with
+// reflection, which is slow, so WithHash is recommended for them.
+//
+// Equal keys must get equal hashes, and +0.0 equals -0.0 although their bits
+// differ, so negative zeros are hashed as positive ones.
+func newDefaultHash[K comparable]() func(key K) uint64 {
+	seed := maphash.MakeSeed()
+
+	return func(key K) uint64 {
+		switch k := any(key).(type) {
+		case string:
+			return maphash.String(seed, k)
+		case int:
+			return mix(uint64(k))
+		case int8:
+			return mix(uint64(k))
+		case int16:
+			return mix(uint64(k))
+		case int32:
+			return mix(uint64(k))
+		case int64:
+			return mix(uint64(k))
+		case uint:
+			return mix(uint64(k))
+		case uint8:
+			return mix(uint64(k))
+		case uint16:
+			return mix(uint64(k))
+		case uint32:
+			return mix(uint64(k))
+		case uint64:
+			return mix(k)
+		case uintptr:
+			return mix(uint64(k))
+		case float32:
+			if k == 0 {
+				k = 0
+			}
+			return mix(uint64(math.Float32bits(k)))
+		case float64:
+			if k == 0 {
+				k = 0
+			}
+			return mix(math.Float64bits(k))
+		default:
+			var h maphash.Hash
+			h.SetSeed(seed)
+			writeKey(&h, reflect.ValueOf(key))
+			return h.Sum64()
+		}
+	}
+}
+
+// writeKey writes the value of a comparable key to h so that equal keys
+// write the same bytes.
+func writeKey(h *maphash.Hash, v reflect.Value) {
+	var buf [8]byte
+	writeUint := func(x uint64) {
+		binary.LittleEndian.PutUint64(buf[:], x)
+		_, _ = h.Write(buf[:])
+	}
+	writeFloat := func(f float64) {
+		if f == 0 {
+			f = 0
+		}
+		writeUint(math.Float64bits(f))
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		writeFloat(real(c))
+		writeFloat(imag(c))
+	case reflect.String:
+		_, _ = h.WriteString(v.String())
+		writeUint(uint64(v.Len()))
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint(uint64(v.Pointer()))
+	case reflect.Interface:
+		if !v.IsNil() {
+			writeKey(h, v.Elem())
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeKey(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			writeKey(h, v.Field(i))
+		}
+	}
+}
+
+// mix is the splitmix64 finalizer, it spreads sequential integers evenly
+// over the shards.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
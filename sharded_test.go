@@ -0,0 +1,174 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func BenchmarkCacheTTL2_GetParallel(b *testing.B) {
+	cache := NewStringWithTTL2(1000)
+
+	for i := 0; i < 1000; i++ {
+		cache.Add(strconv.Itoa(i), i)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Get(strconv.Itoa(i % 1000))
+		}
+	})
+}
+
+func BenchmarkShardedCache_GetParallel(b *testing.B) {
+	cache := NewSharded[string, any](1000)
+
+	for i := 0; i < 1000; i++ {
+		cache.Add(strconv.Itoa(i), i)
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Get(strconv.Itoa(i % 1000))
+		}
+	})
+}
+
+func BenchmarkCacheTTL2_AddParallel(b *testing.B) {
+	cache := NewStringWithTTL2(1000)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Add(strconv.Itoa(i), i)
+		}
+	})
+}
+
+func BenchmarkShardedCache_AddParallel(b *testing.B) {
+	cache := NewSharded[string, any](1000)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cache.Add(strconv.Itoa(i), i)
+		}
+	})
+}
+
+func Test_NewSharded(t *testing.T) {
+	cache := NewSharded[string, any](10, WithShards[string, any](4))
+
+	require.Equal(t, 4, cache.Shards())
+	assert.Equal(t, 10, cache.Cap())
+
+	var shardCaps []int
+	for _, shard := range cache.shards {
+		shardCaps = append(shardCaps, shard.Cap())
+	}
+	assert.Equal(t, []int{3, 3, 2, 2}, shardCaps)
+
+	// there are no more shards than elements
+	assert.Equal(t, 2, NewSharded[string, any](2, WithShards[string, any](8)).Shards())
+	assert.GreaterOrEqual(t, NewSharded[string, any](1000).Shards(), 4)
+}
+
+func Test_ShardedCache(t *testing.T) {
+	cache := NewSharded[int, int](1000, WithShards[int, int](8))
+
+	for i := 0; i < 800; i++ {
+		cache.Add(i, i*10)
+	}
+	for _, shard := range cache.shards {
+		assert.Greater(t, shard.Len(), 50)
+	}
+
+	assert.Equal(t, 800, cache.Len())
+	for i := 0; i < 800; i++ {
+		value, ok := cache.Get(i)
+		require.True(t, ok)
+		require.Equal(t, i*10, value)
+	}
+	cache.Get(-1)
+	cache.Remove(0)
+
+	assert.Equal(t, Stats{
+		Hits:     800,
+		Misses:   1,
+		Inserts:  800,
+		Removals: 1,
+	}, cache.Stats())
+
+	cache.Clear()
+	assert.Equal(t, 0, cache.Len())
+
+	cache.ResetStats()
+	assert.Equal(t, Stats{}, cache.Stats())
+}
+
+func Test_ShardedCache_Hash(t *testing.T) {
+	cache := NewSharded[string, any](8,
+		WithShards[string, any](2),
+		WithHash[string, any](func(key string) uint64 { return uint64(len(key)) }),
+	)
+
+	// keys of even length fill the first shard and evict each other
+	for _, key := range []string{"aa", "bb", "cc", "dd", "ee", "a"} {
+		cache.Add(key, key)
+	}
+
+	assert.Equal(t, 4, cache.shards[0].Len())
+	assert.Equal(t, 1, cache.shards[1].Len())
+	_, ok := cache.Get("aa")
+	assert.False(t, ok)
+	assert.Equal(t, uint64(1), cache.Stats().Evictions)
+}
+
+type point struct{ x, y int }
+
+func Test_ShardedCache_DefaultHash(t *testing.T) {
+	cache := NewSharded[point, string](100, WithShards[point, string](4))
+
+	cache.Add(point{1, 2}, "a")
+	cache.Add(point{2, 1}, "b")
+
+	value, ok := cache.Get(point{1, 2})
+	assert.True(t, ok)
+	assert.Equal(t, "a", value)
+
+	hash := newDefaultHash[float64]()
+	assert.Equal(t, hash(1.5), hash(1.5))
+	assert.NotEqual(t, hash(1), hash(2))
+}
+
+func Test_ShardedCache_DefaultHash_NegativeZero(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+
+	// +0.0 and -0.0 are the same map key, so they must be in the same shard
+	hash64 := newDefaultHash[float64]()
+	assert.Equal(t, hash64(0), hash64(negZero))
+	hash32 := newDefaultHash[float32]()
+	assert.Equal(t, hash32(0), hash32(float32(negZero)))
+
+	type key struct {
+		name string
+		x    float64
+		c    complex128
+		arr  [2]float32
+		v    any
+	}
+	hash := newDefaultHash[key]()
+	zero := key{name: "a", v: 0.0}
+	negative := key{name: "a", x: negZero, c: complex(negZero, negZero), arr: [2]float32{float32(negZero)}, v: negZero}
+	require.True(t, zero == negative)
+	assert.Equal(t, hash(zero), hash(negative))
+	assert.NotEqual(t, hash(zero), hash(key{name: "b", v: 0.0}))
+
+	cache := NewSharded[key, int](100, WithShards[key, int](16))
+	for i := 0; i < 16; i++ {
+		name := strconv.Itoa(i)
+		cache.Add(key{name: name, v: 0.0}, i)
+		cache.Add(key{name: name, x: negZero, v: negZero}, i)
+	}
+	assert.Equal(t, 16, cache.Len())
+}
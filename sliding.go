@@ -0,0 +1,61 @@
+package lrucache
+
+import "time"
+
+// AddWithSlidingTTL adds the value that expires after it was not read for
+// idle. A positive maxLifetime limits how long reads can keep it in the cache.
+func (c *CacheWithTTL[K, V]) AddWithSlidingTTL(key K, value V, idle, maxLifetime time.Duration) {
+	c.mutex.Lock()
+	defer c.unlock()
+
+	if elem := c.add(key, value); elem != nil {
+		c.setSlidingTTL(elem, idle, maxLifetime)
+	}
+}
+
+// AddWithSlidingTTL adds the value that expires after it was not read for
+// idle. A positive maxLifetime limits how long reads can keep it in the cache.
+func (c *CacheWithTTL2[K, V]) AddWithSlidingTTL(key K, value V, idle, maxLifetime time.Duration) {
+	c.UpdateExpirations()
+
+	c.mutex.Lock()
+	defer c.unlock()
+
+	if elem := c.add(key, value); elem != nil {
+		c.setSlidingTTL(elem, idle, maxLifetime)
+	}
+}
+
+// setSlidingTTL must be called with the write lock held.
+func (c *Cache[K, V]) setSlidingTTL(elem *Element[K, V], idle, maxLifetime time.Duration) {
+	now := c.clock.Now()
+
+	elem.idle = idle
+	elem.deadline = time.Time{}
+	if maxLifetime > 0 {
+		elem.deadline = now.Add(maxLifetime)
+	}
+
+	c.expireAt(elem, elem.slidingStaleAt(now))
+}
+
+// slide moves the expiration of a read element forward by its idle timeout.
+// Must be called with the write lock held.
+func (c *Cache[K, V]) slide(elem *Element[K, V]) {
+	now := c.clock.Now()
+
+	// expired elements that are not removed yet stay expired
+	if !now.Before(elem.expiresAt) {
+		return
+	}
+
+	c.expireAt(elem, elem.slidingStaleAt(now))
+}
+
+func (e *Element[K, V]) slidingStaleAt(now time.Time) time.Time {
+	staleAt := now.Add(e.idle)
+	if !e.deadline.IsZero() && staleAt.After(e.deadline) {
+		return e.deadline
+	}
+	return staleAt
+}
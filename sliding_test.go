@@ -0,0 +1,99 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type slidingCache interface {
+	ICacheWithTTL[string, any]
+	AddWithSlidingTTL(key string, value any, idle, maxLifetime time.Duration)
+}
+
+func Test_SlidingExpiration(t *testing.T) {
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name+"/per entry", func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 10, WithClock[string, any](clock)).(slidingCache)
+
+			cache.AddWithSlidingTTL("sliding", 1, time.Millisecond*200, 0)
+			cache.AddWithTTL("fixed", 2, time.Millisecond*200)
+			advance(t, clock, time.Millisecond*50)
+
+			cache.Get("sliding")
+			cache.Get("fixed")
+
+			ttl, _ := cache.TTL("sliding")
+			assert.Equal(t, time.Millisecond*200, ttl)
+			ttl, _ = cache.TTL("fixed")
+			assert.Equal(t, time.Millisecond*150, ttl)
+
+			// the element is removed once it is not read for the idle timeout
+			cache.AddWithSlidingTTL("idle", 3, time.Millisecond*30, 0)
+			for i := 0; i < 5; i++ {
+				advance(t, clock, time.Millisecond*20)
+				_, ok := cache.Get("idle")
+				require.True(t, ok)
+			}
+			advance(t, clock, time.Millisecond*40)
+			_, ok := cache.Get("idle")
+			assert.False(t, ok)
+		})
+
+		t.Run(f.name+"/max lifetime", func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 10, WithClock[string, any](clock)).(slidingCache)
+
+			cache.AddWithSlidingTTL("key", 1, time.Millisecond*200, time.Millisecond*60)
+			ttl, _ := cache.TTL("key")
+			assert.Equal(t, time.Millisecond*60, ttl)
+
+			advance(t, clock, time.Millisecond*50)
+			cache.Get("key")
+			ttl, _ = cache.TTL("key")
+			assert.Equal(t, time.Millisecond*10, ttl)
+
+			advance(t, clock, time.Millisecond*20)
+			_, ok := cache.Get("key")
+			assert.False(t, ok)
+		})
+
+		t.Run(f.name+"/per cache", func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 10,
+				WithSlidingExpiration[string, any](),
+				WithMaxLifetime[string, any](time.Minute),
+				WithClock[string, any](clock),
+			)
+
+			cache.AddWithTTL("key", 1, time.Millisecond*200)
+			advance(t, clock, time.Millisecond*50)
+			cache.Get("key")
+
+			ttl, _ := cache.TTL("key")
+			assert.Equal(t, time.Millisecond*200, ttl)
+
+			// Add drops the expiration, reads do not bring it back
+			cache.Add("key", 2)
+			cache.Get("key")
+			ttl, _ = cache.TTL("key")
+			assert.Equal(t, NoExpiration, ttl)
+		})
+
+		t.Run(f.name+"/fixed TTL replaces sliding", func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 10, WithClock[string, any](clock)).(slidingCache)
+
+			cache.AddWithSlidingTTL("key", 1, time.Millisecond*200, 0)
+			cache.AddWithTTL("key", 2, time.Millisecond*200)
+			advance(t, clock, time.Millisecond*50)
+			cache.Get("key")
+
+			ttl, _ := cache.TTL("key")
+			assert.Equal(t, time.Millisecond*150, ttl)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package lrucache
+
+import "sync/atomic"
+
+// Stats is a snapshot of the cache counters.
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Inserts     uint64 // new keys added
+	Updates     uint64 // values replaced for existing keys
+	Evictions   uint64 // elements displaced because the cache was full
+	Expirations uint64
+	Removals    uint64 // elements removed with Remove
+
+	NegativeHits    uint64 // lookups that found a negative entry
+	NegativeInserts uint64 // negative entries added
+
+	Rejections uint64 // values not added as too large or not admitted by TinyLFU
+}
+
+// HitRatio returns the share of Get calls that found a value, or 0 if there
+// were no calls. Negative hits are not included.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+func (s Stats) add(o Stats) Stats {
+	return Stats{
+		Hits:            s.Hits + o.Hits,
+		Misses:          s.Misses + o.Misses,
+		Inserts:         s.Inserts + o.Inserts,
+		Updates:         s.Updates + o.Updates,
+		Evictions:       s.Evictions + o.Evictions,
+		Expirations:     s.Expirations + o.Expirations,
+		Removals:        s.Removals + o.Removals,
+		NegativeHits:    s.NegativeHits + o.NegativeHits,
+		NegativeInserts: s.NegativeInserts + o.NegativeInserts,
+		Rejections:      s.Rejections + o.Rejections,
+	}
+}
+
+type counters struct {
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	inserts     atomic.Uint64
+	updates     atomic.Uint64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+	removals    atomic.Uint64
+
+	negativeHits    atomic.Uint64
+	negativeInserts atomic.Uint64
+
+	rejections atomic.Uint64
+}
+
+// Stats returns the counters collected since the cache was created or since
+// the last ResetStats call. Counters are read one by one, so a snapshot taken
+// under load may be slightly inconsistent.
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:        c.counters.hits.Load(),
+		Misses:      c.counters.misses.Load(),
+		Inserts:     c.counters.inserts.Load(),
+		Updates:     c.counters.updates.Load(),
+		Evictions:   c.counters.evictions.Load(),
+		Expirations: c.counters.expirations.Load(),
+		Removals:    c.counters.removals.Load(),
+
+		NegativeHits:    c.counters.negativeHits.Load(),
+		NegativeInserts: c.counters.negativeInserts.Load(),
+
+		Rejections: c.counters.rejections.Load(),
+	}
+}
+
+func (c *Cache[K, V]) ResetStats() {
+	c.counters.hits.Store(0)
+	c.counters.misses.Store(0)
+	c.counters.inserts.Store(0)
+	c.counters.updates.Store(0)
+	c.counters.evictions.Store(0)
+	c.counters.expirations.Store(0)
+	c.counters.removals.Store(0)
+	c.counters.negativeHits.Store(0)
+	c.counters.negativeInserts.Store(0)
+	c.counters.rejections.Store(0)
+}
+
+// countRemoval counts an element removed from the cache for the given reason.
+func (c *counters) countRemoval(reason EvictReason) {
+	switch reason {
+	case EvictReasonCapacity:
+		c.evictions.Add(1)
+	case EvictReasonExpired:
+		c.expirations.Add(1)
+	case EvictReasonRemoved:
+		c.removals.Add(1)
+	}
+}
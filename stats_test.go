@@ -0,0 +1,67 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type cacheWithStats interface {
+	Stats() Stats
+	ResetStats()
+}
+
+func Test_Stats(t *testing.T) {
+	for _, f := range cacheFactories() {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			cache := f.new(t, 2)
+			stats := cache.(cacheWithStats)
+
+			assert.Equal(t, Stats{}, stats.Stats())
+			assert.Equal(t, float64(0), stats.Stats().HitRatio())
+
+			cache.Add("first", 1)
+			cache.Add("second", 2)
+			cache.Add("second", 3)
+			cache.Add("third", 4)
+			cache.Get("second")
+			cache.Get("third")
+			cache.Get("first")
+			cache.Remove("third")
+			cache.Remove("random key")
+
+			assert.Equal(t, Stats{
+				Hits:      2,
+				Misses:    1,
+				Inserts:   3,
+				Updates:   1,
+				Evictions: 1,
+				Removals:  1,
+			}, stats.Stats())
+			assert.InDelta(t, 2.0/3.0, stats.Stats().HitRatio(), 1e-9)
+
+			stats.ResetStats()
+			assert.Equal(t, Stats{}, stats.Stats())
+		})
+	}
+
+	for _, f := range cacheWithTTLFactories() {
+		f := f
+		t.Run(f.name+"/expirations", func(t *testing.T) {
+			clock := NewFakeClock(time.Now())
+			cache := f.new(t, 2, WithClock[string, any](clock))
+			stats := cache.(cacheWithStats)
+
+			cache.AddWithTTL("first", 1, time.Millisecond*20)
+
+			advance(t, clock, time.Millisecond*30)
+			_, ok := cache.Get("first")
+			assert.False(t, ok)
+			assert.Equal(t, uint64(1), stats.Stats().Expirations)
+
+			assert.Equal(t, uint64(1), stats.Stats().Inserts)
+			assert.Equal(t, uint64(0), stats.Stats().Evictions)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+//go:build ignore
+
+// gen_zipf_trace writes the synthetic trace used by BenchmarkHitRatio: 50000
+// requests to keys drawn from a Zipf distribution (s = 1.1, 100000 keys) with
+// a scan of 1000 keys that are never requested again after every 5000
+// requests. The seed is fixed, so the trace is the same on every run.
+//
+//	go run testdata/gen_zipf_trace.go testdata/zipf_scan.trace.gz
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: gen_zipf_trace <file>")
+	}
+
+	f, err := os.Create(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	zw, err := gzip.NewWriterLevel(f, gzip.BestCompression)
+	if err != nil {
+		log.Fatal(err)
+	}
+	w := bufio.NewWriter(zw)
+
+	rnd := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(rnd, 1.1, 1, 99999)
+	scan := 1000000
+	for i := 0; i < 50000; i++ {
+		if i%5000 == 4000 {
+			for j := 0; j < 1000; j++ {
+				scan++
+				fmt.Fprintln(w, scan)
+			}
+		}
+		fmt.Fprintln(w, zipf.Uint64())
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
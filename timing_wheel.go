@@ -0,0 +1,135 @@
+package lrucache
+
+import "time"
+
+const (
+	wheelLevels = 5
+	wheelSlots  = 64 // slots of every level, a slot of a level spans a whole level below
+	wheelBits   = 6  // log2(wheelSlots)
+	wheelShift  = 20 // a slot of the first level spans 2^20 ns, about 1 ms
+)
+
+// timingWheel is a hierarchical timing wheel, an alternative to
+// expirationQueue with O(1) schedule and cancel. Levels of 64 slots span
+// about 67 ms, 4.3 s, 4.6 min, 4.9 h and 13 days. An element is put in the
+// slot of its expiration time on the lowest level that spans it. As time
+// passes, the slots passed are emptied: expired elements are removed and the
+// rest are scheduled again on lower levels. Elements expire up to a slot of
+// the first level late.
+type timingWheel[K comparable, V any] struct {
+	slots [wheelLevels * wheelSlots]*Element[K, V] // heads of the slot lists
+	time  int64                                    // time of the last expire, in ns
+	len   int
+}
+
+func newTimingWheel[K comparable, V any](now time.Time) *timingWheel[K, V] {
+	return &timingWheel[K, V]{
+		time: now.UnixNano(),
+	}
+}
+
+// slot returns the index of the slot for an element that expires at t.
+func (w *timingWheel[K, V]) slot(t int64) int {
+	if t < w.time {
+		t = w.time
+	}
+	delta := t - w.time
+
+	level := 0
+	for level < wheelLevels-1 && delta >= 1<<(wheelShift+wheelBits*(level+1)) {
+		level++
+	}
+	// the top level wraps around, its elements are scheduled again when
+	// their slot comes earlier than they expire
+	shift := wheelShift + wheelBits*level
+	return level*wheelSlots + int(t>>shift)&(wheelSlots-1)
+}
+
+func (w *timingWheel[K, V]) schedule(elem *Element[K, V]) {
+	w.cancel(elem)
+
+	index := w.slot(elem.expiresAt.UnixNano())
+	head := w.slots[index]
+	elem.expNext = head
+	if head != nil {
+		head.expPrev = elem
+	}
+	w.slots[index] = elem
+	elem.expQueueIndex = index
+	w.len++
+}
+
+func (w *timingWheel[K, V]) cancel(elem *Element[K, V]) {
+	if elem.expQueueIndex == -1 {
+		return
+	}
+
+	if elem.expPrev != nil {
+		elem.expPrev.expNext = elem.expNext
+	} else {
+		w.slots[elem.expQueueIndex] = elem.expNext
+	}
+	if elem.expNext != nil {
+		elem.expNext.expPrev = elem.expPrev
+	}
+	elem.expPrev, elem.expNext, elem.expQueueIndex = nil, nil, -1
+	w.len--
+}
+
+func (w *timingWheel[K, V]) due(now time.Time) bool {
+	return w.len > 0 && now.UnixNano()>>wheelShift > w.time>>wheelShift
+}
+
+func (w *timingWheel[K, V]) expire(now time.Time, remove func(elem *Element[K, V])) {
+	prev, n := w.time, now.UnixNano()
+	if n <= prev {
+		return
+	}
+	w.time = n
+
+	for level := 0; level < wheelLevels; level++ {
+		shift := wheelShift + wheelBits*level
+		prevTicks, ticks := prev>>shift, n>>shift
+		if ticks == prevTicks {
+			// higher levels have not moved either
+			return
+		}
+
+		steps := ticks - prevTicks + 1
+		if steps > wheelSlots {
+			steps = wheelSlots
+		}
+		for i := int64(0); i < steps; i++ {
+			w.expireSlot(level*wheelSlots+int((prevTicks+i)&(wheelSlots-1)), now, remove)
+		}
+	}
+}
+
+// expireSlot empties the slot, removing the expired elements and scheduling
+// the rest again.
+func (w *timingWheel[K, V]) expireSlot(index int, now time.Time, remove func(elem *Element[K, V])) {
+	elem := w.slots[index]
+	w.slots[index] = nil
+
+	for elem != nil {
+		next := elem.expNext
+		elem.expPrev, elem.expNext, elem.expQueueIndex = nil, nil, -1
+		w.len--
+
+		if elem.expiresAt.Before(now) {
+			remove(elem)
+		} else {
+			w.schedule(elem)
+		}
+		elem = next
+	}
+}
+
+func (w *timingWheel[K, V]) clear() {
+	w.slots = [wheelLevels * wheelSlots]*Element[K, V]{}
+	w.len = 0
+}
+
+func (w *timingWheel[K, V]) Len() int {
+	return w.len
+}
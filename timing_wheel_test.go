@@ -0,0 +1,156 @@
+package lrucache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_TimingWheel(t *testing.T) {
+	w := newTimingWheel[int, int](time.Now())
+	start := time.Unix(0, w.time)
+	rnd := rand.New(rand.NewSource(1))
+
+	scheduled := map[*Element[int, int]]bool{}
+	for i := 0; i < 5000; i++ {
+		elem := &Element[int, int]{key: i, expQueueIndex: -1}
+		// from a microsecond to a few days, some of them rescheduled
+		elem.expiresAt = start.Add(time.Duration(rnd.Int63n(int64(time.Second) << rnd.Intn(19))))
+		w.schedule(elem)
+		if i%3 == 0 {
+			elem.expiresAt = elem.expiresAt.Add(time.Duration(rnd.Int63n(int64(time.Minute))))
+			w.schedule(elem)
+		}
+		scheduled[elem] = true
+	}
+	for elem := range scheduled {
+		if elem.key%5 == 0 {
+			w.cancel(elem)
+			delete(scheduled, elem)
+		}
+	}
+	require.Equal(t, len(scheduled), w.Len())
+
+	now := start
+	for w.Len() > 0 {
+		now = now.Add(time.Duration(rnd.Int63n(int64(time.Hour))))
+		w.expire(now, func(elem *Element[int, int]) {
+			require.True(t, scheduled[elem], elem.key)
+			require.True(t, elem.expiresAt.Before(now))
+			delete(scheduled, elem)
+		})
+
+		// everything that expired a slot ago is removed
+		for elem := range scheduled {
+			require.False(t, elem.expiresAt.Before(now.Add(-time.Millisecond*2)), elem.key)
+		}
+		require.Equal(t, len(scheduled), w.Len())
+	}
+	assert.Empty(t, scheduled)
+}
+
+func Test_TimingWheel_Precision(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewWithTTL2[string, int](10, WithTimingWheel[string, int](), WithClock[string, int](clock))
+
+	cache.AddWithTTL("first", 1, time.Millisecond*5)
+	cache.AddWithTTL("second", 2, time.Minute)
+	cache.AddWithTTL("third", 3, time.Millisecond*50)
+	cache.Remove("third")
+	assert.Equal(t, 2, cache.expQueue.Len())
+
+	// elements expire up to a slot of the first level late
+	clock.Advance(time.Millisecond * 5)
+	_, ok := cache.Get("first")
+	assert.True(t, ok)
+	clock.Advance(time.Millisecond * 2)
+	_, ok = cache.Get("first")
+	assert.False(t, ok)
+	assert.Equal(t, 1, cache.expQueue.Len())
+
+	ttl, ok := cache.TTL("second")
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute-time.Millisecond*7, ttl)
+}
+
+func expirationBackends() map[string]func() expirations[int, int] {
+	return map[string]func() expirations[int, int]{
+		"Heap":        func() expirations[int, int] { return newExpirationQueue[int, int]() },
+		"TimingWheel": func() expirations[int, int] { return newTimingWheel[int, int](time.Now()) },
+	}
+}
+
+// BenchmarkExpirations_Schedule sets a new TTL for elements of a queue of a
+// million elements, like AddWithTTL of existing keys does.
+func BenchmarkExpirations_Schedule(b *testing.B) {
+	for name, backend := range expirationBackends() {
+		b.Run(name, func(b *testing.B) {
+			q := backend()
+			now := time.Now()
+			rnd := rand.New(rand.NewSource(1))
+
+			elems := make([]*Element[int, int], 1_000_000)
+			for i := range elems {
+				elems[i] = &Element[int, int]{key: i, expQueueIndex: -1, expiresAt: now.Add(time.Duration(rnd.Int63n(int64(time.Hour))))}
+				q.schedule(elems[i])
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				elem := elems[i%len(elems)]
+				elem.expiresAt = now.Add(time.Duration(rnd.Int63n(int64(time.Hour))))
+				q.schedule(elem)
+			}
+		})
+	}
+}
+
+// BenchmarkExpirations_Churn adds and removes elements with TTL, keeping a
+// million of them scheduled.
+func BenchmarkExpirations_Churn(b *testing.B) {
+	for name, backend := range expirationBackends() {
+		b.Run(name, func(b *testing.B) {
+			q := backend()
+			now := time.Now()
+			rnd := rand.New(rand.NewSource(1))
+
+			elems := make([]*Element[int, int], 1_000_000)
+			for i := range elems {
+				elems[i] = &Element[int, int]{key: i, expQueueIndex: -1, expiresAt: now.Add(time.Duration(rnd.Int63n(int64(time.Hour))))}
+				q.schedule(elems[i])
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				elem := elems[rnd.Intn(len(elems))]
+				q.cancel(elem)
+				elem.expiresAt = now.Add(time.Duration(rnd.Int63n(int64(time.Hour))))
+				q.schedule(elem)
+			}
+		})
+	}
+}
+
+func BenchmarkCacheWithTTL2_AddWithTTL(b *testing.B) {
+	for name, opts := range map[string][]Option[string, int]{
+		"Heap":        nil,
+		"TimingWheel": {WithTimingWheel[string, int]()},
+	} {
+		b.Run(name, func(b *testing.B) {
+			cache := NewWithTTL2[string, int](1_000_000, opts...)
+			keys := make([]string, 1_000_000)
+			for i := range keys {
+				keys[i] = strconv.Itoa(i)
+				cache.AddWithTTL(keys[i], i, time.Hour+time.Duration(i)*time.Millisecond)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.AddWithTTL(keys[i%len(keys)], i, time.Hour+time.Duration(i%7919)*time.Millisecond)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package lrucache
+
+// tinyLFU estimates how often keys were accessed recently and admits a new
+// key to a full cache only if it is more frequent than the element it would
+// evict. Keys seen once are kept in the doorkeeper bloom filter and get to the
+// Count-Min sketch on the second access, so one-off keys don't pollute it.
+// All methods must be called with the write lock of the cache held.
+type tinyLFU[K comparable] struct {
+	hash       func(key K) uint64
+	sketch     countMinSketch
+	doorkeeper bloomFilter
+
+	additions  int
+	sampleSize int // additions after which all counts are halved
+}
+
+func newTinyLFU[K comparable](cap int, hash func(key K) uint64) *tinyLFU[K] {
+	if cap < 1 {
+		cap = 1
+	}
+
+	return &tinyLFU[K]{
+		hash:       hash,
+		sketch:     newCountMinSketch(cap),
+		doorkeeper: newBloomFilter(cap),
+		sampleSize: cap * 10,
+	}
+}
+
+// resize sizes the filter for a new capacity of the cache. The counts and
+// the doorkeeper are carried over, see countMinSketch.resized.
+func (t *tinyLFU[K]) resize(cap int) {
+	if cap < 1 {
+		cap = 1
+	}
+
+	t.sketch = t.sketch.resized(cap)
+	t.doorkeeper = t.doorkeeper.resized(cap)
+	t.sampleSize = cap * 10
+	if t.additions >= t.sampleSize {
+		t.reset()
+	}
+}
+
+// keyHash returns the hash of the key. It is mixed again because the
+// ShardedCache picks shards by the low bits of the same hash.
+func (t *tinyLFU[K]) keyHash(key K) uint64 {
+	return mix(t.hash(key))
+}
+
+// increment records an access of the key.
+func (t *tinyLFU[K]) increment(key K) {
+	h := t.keyHash(key)
+
+	if t.doorkeeper.add(h) {
+		t.sketch.increment(h)
+	}
+
+	t.additions++
+	if t.additions >= t.sampleSize {
+		t.reset()
+	}
+}
+
+// estimate returns the estimated number of recent accesses of the key.
+func (t *tinyLFU[K]) estimate(key K) int {
+	h := t.keyHash(key)
+
+	n := int(t.sketch.estimate(h))
+	if t.doorkeeper.contains(h) {
+		n++
+	}
+	return n
+}
+
+// admit reports whether the candidate should replace the victim.
+func (t *tinyLFU[K]) admit(candidate, victim K) bool {
+	return t.estimate(candidate) > t.estimate(victim)
+}
+
+// reset ages the counts so that keys popular in the past make way for keys
+// popular now.
+func (t *tinyLFU[K]) reset() {
+	t.additions = 0
+	t.sketch.halve()
+	t.doorkeeper.clear()
+}
+
+const (
+	sketchDepth    = 4
+	sketchMinWidth = 16
+	sketchMaxCount = 15
+)
+
+// countMinSketch counts accesses in sketchDepth rows of saturating counters
+// and estimates the count of a key as the minimum of its counters.
+type countMinSketch struct {
+	rows [sketchDepth][]uint8
+	mask uint64
+}
+
+func newCountMinSketch(width int) countMinSketch {
+	n := nextPowerOfTwo(width)
+	if n < sketchMinWidth {
+		n = sketchMinWidth
+	}
+
+	var s countMinSketch
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, n)
+	}
+	s.mask = uint64(n - 1)
+	return s
+}
+
+// index returns the counter of the hash in row i, double hashing derives the
+// row hashes from the two halves of the hash.
+func (s *countMinSketch) index(h uint64, i int) uint64 {
+	return (h + uint64(i)*(h>>32|1)) & s.mask
+}
+
+func (s *countMinSketch) increment(h uint64) {
+	for i := range s.rows {
+		if c := &s.rows[i][s.index(h, i)]; *c < sketchMaxCount {
+			*c++
+		}
+	}
+}
+
+func (s *countMinSketch) estimate(h uint64) uint8 {
+	min := uint8(sketchMaxCount)
+	for i := range s.rows {
+		if c := s.rows[i][s.index(h, i)]; c < min {
+			min = c
+		}
+	}
+	return min
+}
+
+// resized returns a copy of the sketch with the given width. Indices are the
+// low bits of the row hashes, so a wider sketch repeats the counters and a
+// narrower one adds up the counters that share an index, as if the sketch had
+// had the new width from the start.
+func (s *countMinSketch) resized(width int) countMinSketch {
+	r := newCountMinSketch(width)
+	for i := range r.rows {
+		if len(r.rows[i]) >= len(s.rows[i]) {
+			for j := range r.rows[i] {
+				r.rows[i][j] = s.rows[i][uint64(j)&s.mask]
+			}
+			continue
+		}
+
+		for j, c := range s.rows[i] {
+			n := &r.rows[i][uint64(j)&r.mask]
+			if sum := int(*n) + int(c); sum < sketchMaxCount {
+				*n = uint8(sum)
+			} else {
+				*n = sketchMaxCount
+			}
+		}
+	}
+	return r
+}
+
+func (s *countMinSketch) halve() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+}
+
+// bloomFilter is a bloom filter with two hash functions derived from one hash.
+type bloomFilter struct {
+	bits []uint64
+	mask uint64
+}
+
+func newBloomFilter(n int) bloomFilter {
+	// about 8 bits per key
+	words := nextPowerOfTwo((n*8 + 63) / 64)
+	return bloomFilter{
+		bits: make([]uint64, words),
+		mask: uint64(words*64 - 1),
+	}
+}
+
+// add adds the hash and reports whether it was already present.
+func (f *bloomFilter) add(h uint64) bool {
+	present := true
+	for _, bit := range f.positions(h) {
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if f.bits[word]&mask == 0 {
+			present = false
+			f.bits[word] |= mask
+		}
+	}
+	return present
+}
+
+func (f *bloomFilter) contains(h uint64) bool {
+	for _, bit := range f.positions(h) {
+		if f.bits[bit/64]&(uint64(1)<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *bloomFilter) positions(h uint64) [2]uint64 {
+	return [2]uint64{h & f.mask, (h >> 32) & f.mask}
+}
+
+// resized returns a copy of the filter sized for n keys, the bits are carried
+// over the same way as the counters in countMinSketch.resized.
+func (f *bloomFilter) resized(n int) bloomFilter {
+	r := newBloomFilter(n)
+	if len(r.bits) >= len(f.bits) {
+		for i := range r.bits {
+			r.bits[i] = f.bits[i%len(f.bits)]
+		}
+	} else {
+		for i, word := range f.bits {
+			r.bits[i%len(r.bits)] |= word
+		}
+	}
+	return r
+}
+
+func (f *bloomFilter) clear() {
+	for i := range f.bits {
+		f.bits[i] = 0
+	}
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
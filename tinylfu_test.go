@@ -0,0 +1,202 @@
+package lrucache
+
+import (
+	"bufio"
+	"compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+//go:generate go run testdata/gen_zipf_trace.go testdata/zipf_scan.trace.gz
+
+// loadTrace reads a trace of gzipped keys, one per line. The synthetic trace
+// in testdata, written by testdata/gen_zipf_trace.go with a fixed seed, mixes
+// Zipf-distributed keys with scans of keys read only once.
+func loadTrace(tb testing.TB, name string) []string {
+	f, err := os.Open("testdata/" + name)
+	require.NoError(tb, err)
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	require.NoError(tb, err)
+
+	var keys []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		keys = append(keys, scanner.Text())
+	}
+	require.NoError(tb, scanner.Err())
+
+	return keys
+}
+
+// byteHash hashes single letter keys without collisions in small sketches.
+func byteHash(key string) uint64 {
+	return mix(uint64(key[0]))
+}
+
+// traceHitRatio replays the trace reading through the cache and returns the
+// hit ratio.
+func traceHitRatio(cache *Cache[string, int], keys []string) float64 {
+	for i, key := range keys {
+		if _, ok := cache.Get(key); !ok {
+			cache.Add(key, i)
+		}
+	}
+	return cache.Stats().HitRatio()
+}
+
+func Test_TinyLFU_Sketch(t *testing.T) {
+	f := newTinyLFU[string](100, byteHash)
+
+	assert.Equal(t, 0, f.estimate("a"))
+
+	// the first access goes to the doorkeeper
+	f.increment("a")
+	assert.Equal(t, 1, f.estimate("a"))
+
+	for i := 0; i < 5; i++ {
+		f.increment("a")
+	}
+	assert.Equal(t, 6, f.estimate("a"))
+	assert.True(t, f.admit("a", "b"))
+	assert.False(t, f.admit("b", "a"))
+
+	// counters saturate
+	for i := 0; i < 100; i++ {
+		f.increment("a")
+	}
+	assert.Equal(t, sketchMaxCount+1, f.estimate("a"))
+}
+
+func Test_TinyLFU_Aging(t *testing.T) {
+	f := newTinyLFU[string](10, byteHash)
+
+	for i := 0; i < 9; i++ {
+		f.increment("a")
+	}
+	assert.Equal(t, 9, f.estimate("a"))
+
+	// the 100th increment halves the counts and clears the doorkeeper
+	for i := 0; i < 91; i++ {
+		f.increment("b")
+	}
+	assert.Equal(t, 4, f.estimate("a"))
+	assert.Equal(t, 7, f.estimate("b"))
+	assert.Equal(t, 0, f.additions)
+}
+
+func Test_TinyLFU_Resize(t *testing.T) {
+	f := newTinyLFU[string](10, byteHash)
+
+	for i := 0; i < 5; i++ {
+		f.increment("a")
+	}
+	f.increment("b")
+
+	// the estimates survive growing
+	f.resize(1000)
+	assert.Equal(t, 1024, len(f.sketch.rows[0]))
+	assert.Equal(t, 10000, f.sampleSize)
+	assert.Equal(t, 5, f.estimate("a"))
+	assert.Equal(t, 1, f.estimate("b"))
+
+	// and shrinking never lowers them
+	f.resize(10)
+	assert.Equal(t, sketchMinWidth, len(f.sketch.rows[0]))
+	assert.Equal(t, 100, f.sampleSize)
+	assert.GreaterOrEqual(t, f.estimate("a"), 5)
+	assert.GreaterOrEqual(t, f.estimate("b"), 1)
+
+	// the sample size follows the capacity, the additions made before the
+	// resize count towards it
+	for i := 0; i < 94; i++ {
+		f.increment("c")
+	}
+	assert.Equal(t, 0, f.additions)
+}
+
+func Test_Resize_TinyLFU(t *testing.T) {
+	cache := New[string, int](10, WithTinyLFU[string, int]())
+	require.NoError(t, cache.Resize(1000))
+
+	assert.Equal(t, 1000*10, cache.admission.sampleSize)
+	assert.Equal(t, 1024, len(cache.admission.sketch.rows[0]))
+}
+
+func Test_WithTinyLFU(t *testing.T) {
+	cache := New[string, int](2, WithTinyLFU[string, int](), WithHash[string, int](byteHash))
+
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+	for i := 0; i < 3; i++ {
+		cache.Add("a", 1)
+		cache.Add("b", 2)
+	}
+
+	// a key seen once does not displace frequent ones
+	cache.Add("c", 3)
+	_, ok := cache.Get("c")
+	assert.False(t, ok)
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, uint64(1), cache.Stats().Rejections)
+
+	// until it gets frequent enough
+	for i := 0; i < 10; i++ {
+		cache.Add("c", 3)
+	}
+	value, ok := cache.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+}
+
+func Test_WithTinyLFU_TTL(t *testing.T) {
+	cache := NewWithTTL2[string, int](1, WithTinyLFU[string, int](), WithHash[string, int](byteHash))
+
+	cache.AddWithTTL("a", 1, time.Minute)
+	cache.AddWithTTL("a", 1, time.Minute)
+
+	// rejected keys are neither stored nor scheduled for expiration
+	cache.AddWithTTL("b", 2, time.Minute)
+	cache.AddNegative("c", nil, time.Minute)
+	cache.AddWithSlidingTTL("d", 4, time.Minute, 0)
+
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, 1, cache.expQueue.Len())
+	assert.Equal(t, uint64(3), cache.Stats().Rejections)
+}
+
+func Test_WithTinyLFU_HitRatio(t *testing.T) {
+	keys := loadTrace(t, "zipf_scan.trace.gz")
+
+	lru := traceHitRatio(New[string, int](1000), keys)
+	tinyLFU := traceHitRatio(New[string, int](1000, WithTinyLFU[string, int]()), keys)
+
+	assert.Greater(t, tinyLFU, lru)
+}
+
+func BenchmarkHitRatio(b *testing.B) {
+	keys := loadTrace(b, "zipf_scan.trace.gz")
+
+	for _, f := range policyFactories() {
+		for _, admission := range []bool{false, true} {
+			name := f.name
+			opts := []Option[string, int]{WithEvictionPolicy(f.new)}
+			if admission {
+				name += "+TinyLFU"
+				opts = append(opts, WithTinyLFU[string, int]())
+			}
+
+			b.Run(name, func(b *testing.B) {
+				var ratio float64
+				for i := 0; i < b.N; i++ {
+					ratio = traceHitRatio(New[string, int](1000, opts...), keys)
+				}
+				b.ReportMetric(ratio*100, "hit%")
+			})
+		}
+	}
+}